@@ -0,0 +1,100 @@
+package key
+
+import "time"
+
+// Metadata holds the optional parameters describing a key: RFC 7517 Section 4
+type Metadata struct {
+	// Use is the intended use of the public key (sig, enc): JWK 4.2
+	Use string
+	// KeyOperations lists the operations the key is intended for: JWK 4.3
+	KeyOperations []string
+	// IssuedAt is the key creation date, persisted as the JWK "iat" claim
+	IssuedAt time.Time
+	// X509URL refers to the X.509 certificate chain: JWK 4.6
+	X509URL string
+	// X509CertChain is the base64 encoded DER X.509 certificate chain: JWK 4.7
+	X509CertChain []string
+	// X509Sha1Thumbprint is the certificate SHA-1 thumbprint: JWK 4.8
+	X509Sha1Thumbprint string
+	// X509CertSha256Thumbprint is the certificate SHA-256 thumbprint: JWK 4.9
+	X509CertSha256Thumbprint string
+}
+
+// attributes holds the properties shared by all key types
+type attributes struct {
+	// kid overrides the computed key identifier when set
+	kid      string
+	metadata Metadata
+}
+
+// newAttributes returns the attributes of a key created now, the date is
+// truncated to the second as JWK "iat" has no sub-second precision
+func newAttributes() attributes {
+	return attributes{
+		metadata: Metadata{IssuedAt: time.Now().UTC().Truncate(time.Second)},
+	}
+}
+
+// attributed is implemented by keys embedding attributes
+type attributed interface {
+	load(*rawJWK)
+	fill(*rawJWK)
+}
+
+func (a *attributes) Metadata() *Metadata {
+	return &a.metadata
+}
+
+// CreatedAt returns the key creation date, zero when unknown
+func (a *attributes) CreatedAt() time.Time {
+	return a.metadata.IssuedAt
+}
+
+func (a *attributes) setID(id string) {
+	a.kid = id
+}
+
+// clone returns a deep copy of the attributes
+func (a *attributes) clone() attributes {
+	c := *a
+	if a.metadata.KeyOperations != nil {
+		c.metadata.KeyOperations = append([]string{}, a.metadata.KeyOperations...)
+	}
+	if a.metadata.X509CertChain != nil {
+		c.metadata.X509CertChain = append([]string{}, a.metadata.X509CertChain...)
+	}
+	return c
+}
+
+// load restores attributes from the JWK representation
+func (a *attributes) load(raw *rawJWK) {
+	// Keep the declared identifier, it could have been assigned by a strategy
+	if len(raw.KeyID) > 0 {
+		a.kid = raw.KeyID
+	}
+
+	a.metadata = Metadata{
+		Use:                      raw.PublicKeyUse,
+		KeyOperations:            raw.KeyOperations,
+		X509URL:                  raw.X509URL,
+		X509CertChain:            raw.X509CertChain,
+		X509Sha1Thumbprint:       raw.X509Sha1Thumbprint,
+		X509CertSha256Thumbprint: raw.X509CertSha256Thumbprint,
+	}
+	if raw.IssuedAt > 0 {
+		a.metadata.IssuedAt = time.Unix(raw.IssuedAt, 0).UTC()
+	}
+}
+
+// fill writes attributes to the JWK representation
+func (a *attributes) fill(r *rawJWK) {
+	r.PublicKeyUse = a.metadata.Use
+	r.KeyOperations = a.metadata.KeyOperations
+	r.X509URL = a.metadata.X509URL
+	r.X509CertChain = a.metadata.X509CertChain
+	r.X509Sha1Thumbprint = a.metadata.X509Sha1Thumbprint
+	r.X509CertSha256Thumbprint = a.metadata.X509CertSha256Thumbprint
+	if !a.metadata.IssuedAt.IsZero() {
+		r.IssuedAt = a.metadata.IssuedAt.Unix()
+	}
+}
@@ -0,0 +1,79 @@
+package key
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestMetadata_RoundTrip(t *testing.T) {
+	RegisterTestingT(t)
+
+	input := `{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"alg": "EdDSA",
+		"kid": "metadata",
+		"use": "sig",
+		"key_ops": ["sign", "verify"],
+		"iat": 1500000000,
+		"x5u": "https://example.com/chain.pem",
+		"x5c": ["MIIB", "MIIC"],
+		"x5t": "dGh1bWJwcmludA",
+		"x5t#S256": "c2hhMjU2dGh1bWJwcmludA",
+		"d": "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+		"x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	}`
+
+	key, err := FromString([]byte(input))
+	Expect(err).To(BeNil())
+
+	md := key.Metadata()
+	Expect(md.Use).To(Equal("sig"))
+	Expect(md.KeyOperations).To(Equal([]string{"sign", "verify"}))
+	Expect(md.IssuedAt).To(Equal(time.Unix(1500000000, 0).UTC()))
+	Expect(md.X509URL).To(Equal("https://example.com/chain.pem"))
+	Expect(md.X509CertChain).To(Equal([]string{"MIIB", "MIIC"}))
+	Expect(md.X509Sha1Thumbprint).To(Equal("dGh1bWJwcmludA"))
+	Expect(md.X509CertSha256Thumbprint).To(Equal("c2hhMjU2dGh1bWJwcmludA"))
+
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+	Expect(out).To(MatchJSON(input))
+
+	// Public key keeps an independent copy of the metadata
+	pub := key.Public()
+	Expect(pub.Metadata().Use).To(Equal("sig"))
+	pub.Metadata().KeyOperations[0] = "verify"
+	Expect(key.Metadata().KeyOperations[0]).To(Equal("sign"))
+}
+
+func TestCreatedAt(t *testing.T) {
+	RegisterTestingT(t)
+
+	before := time.Now().UTC().Truncate(time.Second)
+	forEachKey(func(alg string, k Key) {
+		created := k.CreatedAt()
+		Expect(created).To(BeTemporally(">=", before), alg)
+		Expect(created).To(BeTemporally("<=", time.Now()), alg)
+		Expect(k.Public().CreatedAt()).To(Equal(created), alg)
+		Expect(Age(k)).To(BeNumerically(">=", 0), alg)
+
+		// Persisted through the JWK "iat"
+		out, err := json.Marshal(k)
+		Expect(err).To(BeNil(), alg)
+		Expect(string(out)).To(ContainSubstring(`"iat":`), alg)
+
+		loaded, err := FromString(out)
+		Expect(err).To(BeNil(), alg)
+		Expect(loaded.CreatedAt()).To(Equal(created), alg)
+	})
+
+	// Unknown creation date
+	k, err := FromString([]byte(`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	Expect(err).To(BeNil())
+	Expect(k.CreatedAt().IsZero()).To(BeTrue())
+	Expect(Age(k)).To(BeZero())
+}
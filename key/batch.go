@@ -0,0 +1,119 @@
+package key
+
+import (
+	"github.com/oasisprotocol/curve25519-voi/primitives/ed25519"
+)
+
+// BatchVerifier accumulates (key, message, signature) entries and verifies
+// them together. Other keys than Ed25519 fall back to their Verify method.
+//
+// Ed25519 signatures are checked in a single batch using the ZIP-215 rules
+// (cofactored equation, non-canonical point encodings allowed), the only ones
+// under which batch and single verification agree. The accepted signatures
+// are a superset of the ones accepted by Verify (cofactorless equation, as
+// crypto/ed25519): they only differ on crafted signatures using small order
+// or non-canonically encoded points, which no honest signer produces. Use
+// Verify when the exact crypto/ed25519 behavior is required, as a consensus
+// check would. A BatchVerifier is not safe for concurrent use.
+type BatchVerifier struct {
+	entries []batchEntry
+}
+
+type batchEntry struct {
+	key     Key
+	message []byte
+	sig     []byte
+	opts    *SignOptions
+}
+
+// NewBatchVerifier returns an empty batch verifier
+func NewBatchVerifier() *BatchVerifier {
+	return &BatchVerifier{}
+}
+
+// Add queues a signature to verify with the given key
+func (v *BatchVerifier) Add(k Key, message, sig []byte) {
+	v.AddWithOptions(k, message, sig, nil)
+}
+
+// AddWithOptions queues a signature produced with SignWithOptions
+func (v *BatchVerifier) AddWithOptions(k Key, message, sig []byte, opts *SignOptions) {
+	v.entries = append(v.entries, batchEntry{
+		key:     k,
+		message: message,
+		sig:     sig,
+		opts:    opts,
+	})
+}
+
+// Len returns the count of queued entries
+func (v *BatchVerifier) Len() int {
+	return len(v.entries)
+}
+
+// Reset removes all queued entries
+func (v *BatchVerifier) Reset() {
+	v.entries = v.entries[:0]
+}
+
+// Verify checks all queued entries, and returns true if all of them are valid,
+// along with the result of each entry in insertion order. An entry whose key
+// could not verify (missing public key, operation not permitted, unsupported
+// options) is reported as invalid. An empty batch is never valid.
+func (v *BatchVerifier) Verify() (bool, []bool) {
+	if len(v.entries) == 0 {
+		return false, nil
+	}
+
+	var (
+		valid    = make([]bool, len(v.entries))
+		batch    = ed25519.NewBatchVerifierWithCapacity(len(v.entries))
+		batched  []int
+		allValid = true
+	)
+
+	for i, e := range v.entries {
+		if opts, ok := batchEd25519Options(e); ok {
+			batch.AddWithOptions(ed25519.PublicKey(e.key.(*ed25519Key).pub), e.message, e.sig, opts)
+			batched = append(batched, i)
+			continue
+		}
+
+		ok, err := e.key.VerifyWithOptions(e.message, e.sig, e.opts)
+		valid[i] = ok && err == nil
+		allValid = allValid && valid[i]
+	}
+
+	if len(batched) > 0 {
+		_, results := batch.Verify(nil)
+		for j, i := range batched {
+			valid[i] = results[j]
+			allValid = allValid && valid[i]
+		}
+	}
+
+	return allValid, valid
+}
+
+// -----------------------------------------------------------------------------
+
+// batchEd25519Options returns the batch options of an Ed25519 entry, entries
+// that could not be batched are verified individually
+func batchEd25519Options(e batchEntry) (*ed25519.Options, bool) {
+	k, ok := e.key.(*ed25519Key)
+	if !ok || !k.HasPublic() || !k.permits(OperationVerify) {
+		return nil, false
+	}
+
+	// Cofactorless entries would be verified one by one
+	opts := &ed25519.Options{Verify: ed25519.VerifyOptionsZIP_215}
+	if !e.opts.isZero() {
+		o, err := ed25519Options(e.message, e.opts)
+		if err != nil {
+			return nil, false
+		}
+		opts.Hash, opts.Context = o.Hash, o.Context
+	}
+
+	return opts, true
+}
@@ -0,0 +1,163 @@
+package key
+
+import (
+	"crypto"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestBatchVerifier(t *testing.T) {
+	RegisterTestingT(t)
+
+	v := NewBatchVerifier()
+	ok, results := v.Verify()
+	Expect(ok).To(BeFalse())
+	Expect(results).To(BeEmpty())
+
+	for i := 0; i < 8; i++ {
+		k, _ := Ed25519()
+		message := []byte(fmt.Sprintf("event-%d", i))
+		sig, _ := k.Sign(message)
+		v.Add(k.Public(), message, sig)
+	}
+
+	es, _ := ES256()
+	sig, _ := es.Sign([]byte("event"))
+	v.Add(es.Public(), []byte("event"), sig)
+
+	ed, _ := Ed25519()
+	digest := sha512.Sum512([]byte("artifact"))
+	opts := &SignOptions{Hash: crypto.SHA512, Context: "artifact"}
+	sig, _ = ed.SignWithOptions(digest[:], opts)
+	v.AddWithOptions(ed.Public(), digest[:], sig, opts)
+
+	Expect(v.Len()).To(Equal(10))
+	ok, results = v.Verify()
+	Expect(ok).To(BeTrue())
+	Expect(results).To(HaveLen(10))
+
+	// Invalid entries are reported
+	sig, _ = ed.Sign([]byte("event"))
+	v.Add(ed.Public(), []byte("tampered"), sig)
+	v.Add(es.Public(), []byte("tampered"), sig)
+
+	restricted := ed.Public()
+	restricted.Metadata().Use = "enc"
+	v.Add(restricted, []byte("event"), sig)
+
+	ok, results = v.Verify()
+	Expect(ok).To(BeFalse())
+	Expect(results[:10]).NotTo(ContainElement(false))
+	Expect(results[10:]).To(Equal([]bool{false, false, false}))
+
+	v.Reset()
+	Expect(v.Len()).To(Equal(0))
+}
+
+func TestBatchVerifier_ZIP215(t *testing.T) {
+	RegisterTestingT(t)
+
+	identity := append([]byte{0x01}, make([]byte, 31)...)
+	order2, _ := hex.DecodeString("ecffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff7f")
+	zero := make([]byte, 32)
+
+	k, _ := Ed25519()
+	sig, _ := k.Sign([]byte("event"))
+
+	// S + L, non-canonical scalar
+	s := new(big.Int).SetBytes(reverse(sig[32:]))
+	s.Add(s, new(big.Int).SetBytes(reverse([]byte{
+		0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58, 0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+	})))
+	nonCanonical := append(append([]byte{}, sig[:32]...), reverse(s.FillBytes(make([]byte, 32)))...)
+
+	vectors := []struct {
+		name  string
+		pub   []byte
+		sig   []byte
+		valid bool
+		batch bool
+	}{
+		{"valid", k.(*ed25519Key).pub, sig, true, true},
+		{"small order A and R", identity, append(append([]byte{}, identity...), zero...), true, true},
+		{"small order R only accepted by the cofactored equation", identity, append(append([]byte{}, order2...), zero...), false, true},
+		{"non-canonical S", k.(*ed25519Key).pub, nonCanonical, false, false},
+	}
+
+	for _, vector := range vectors {
+		pub := &ed25519Key{pub: vector.pub}
+
+		valid, err := pub.Verify([]byte("event"), vector.sig)
+		Expect(err).To(BeNil(), vector.name)
+		Expect(valid).To(Equal(vector.valid), vector.name)
+
+		v := NewBatchVerifier()
+		v.Add(pub, []byte("event"), vector.sig)
+		_, results := v.Verify()
+		Expect(results).To(Equal([]bool{vector.batch}), vector.name)
+	}
+}
+
+func BenchmarkBatchVerifier(b *testing.B) {
+	keys, messages, sigs := benchmarkSignatures(b, 64)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		v := NewBatchVerifier()
+		for j := range keys {
+			v.Add(keys[j], messages[j], sigs[j])
+		}
+		if ok, _ := v.Verify(); !ok {
+			b.Fatal("invalid batch")
+		}
+	}
+}
+
+func BenchmarkBatchVerifier_Serial(b *testing.B) {
+	keys, messages, sigs := benchmarkSignatures(b, 64)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range keys {
+			if ok, err := keys[j].Verify(messages[j], sigs[j]); !ok || err != nil {
+				b.Fatal("invalid signature")
+			}
+		}
+	}
+}
+
+// benchmarkSignatures returns n Ed25519 public keys with a signed message each
+func benchmarkSignatures(b *testing.B, n int) ([]Key, [][]byte, [][]byte) {
+	var (
+		keys     = make([]Key, n)
+		messages = make([][]byte, n)
+		sigs     = make([][]byte, n)
+	)
+	for i := 0; i < n; i++ {
+		k, err := Ed25519()
+		if err != nil {
+			b.Fatal(err)
+		}
+		messages[i] = []byte(fmt.Sprintf("event-%d", i))
+		if sigs[i], err = k.Sign(messages[i]); err != nil {
+			b.Fatal(err)
+		}
+		keys[i] = k.Public()
+	}
+	return keys, messages, sigs
+}
+
+// reverse returns a reversed copy of the little-endian encoding
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
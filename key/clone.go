@@ -0,0 +1,103 @@
+package key
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"math/big"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// cloner is implemented by keys able to copy their private material
+type cloner interface {
+	deepCopy() (Key, error)
+}
+
+// Clone returns an independent copy of the key, destroying one of them leaves
+// the other one usable. The copy of a key in locked memory is also locked,
+// destroy it once no longer needed to release the locked pages early.
+func Clone(k Key) (Key, error) {
+	c, ok := k.(cloner)
+	if !ok {
+		return nil, ErrInvalidOperationNotSupported
+	}
+	return c.deepCopy()
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ed25519Key) deepCopy() (Key, error) {
+	c := &ed25519Key{attributes: k.attributes.clone(), pub: k.pub}
+	if k.HasPrivate() {
+		c.priv = append(c.priv, k.priv...)
+	}
+	if k.locked {
+		if err := c.lockMemory(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (k *ed448Key) deepCopy() (Key, error) {
+	c := &ed448Key{attributes: k.attributes.clone(), pub: k.pub}
+	if k.HasPrivate() {
+		c.priv = append(c.priv, k.priv...)
+	}
+	if k.locked {
+		if err := c.lockMemory(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (k *hmacKey) deepCopy() (Key, error) {
+	c := &hmacKey{attributes: k.attributes.clone(), params: k.params}
+	if k.HasPrivate() {
+		c.secret = append(c.secret, k.secret...)
+	}
+	if k.locked {
+		if err := c.lockMemory(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (k *ecdsaKey) deepCopy() (Key, error) {
+	c := &ecdsaKey{attributes: k.attributes.clone(), params: k.params, pub: k.pub}
+	if k.HasPrivate() {
+		c.priv = &ecdsa.PrivateKey{PublicKey: k.priv.PublicKey, D: new(big.Int).Set(k.priv.D)}
+	}
+	return c, nil
+}
+
+func (k *rsaKey) deepCopy() (Key, error) {
+	c := &rsaKey{attributes: k.attributes.clone(), params: k.params, pub: k.pub}
+	if k.HasPrivate() {
+		c.priv = &rsa.PrivateKey{PublicKey: k.priv.PublicKey, D: new(big.Int).Set(k.priv.D)}
+		for _, p := range k.priv.Primes {
+			c.priv.Primes = append(c.priv.Primes, new(big.Int).Set(p))
+		}
+		c.priv.Precompute()
+	}
+	return c, nil
+}
+
+func (k *secp256k1Key) deepCopy() (Key, error) {
+	c := &secp256k1Key{attributes: k.attributes.clone(), recoverable: k.recoverable, pub: k.pub}
+	if k.HasPrivate() {
+		var s secp256k1.ModNScalar
+		s.Set(&k.priv.Key)
+		c.priv = secp256k1.NewPrivateKey(&s)
+		s.Zero()
+	}
+	return c, nil
+}
+
+// The private scalar of crypto/ecdh keys is immutable and never wiped, it is
+// shared by the copies
+func (k *ecdhKey) deepCopy() (Key, error) {
+	return &ecdhKey{attributes: k.attributes.clone(), alg: k.alg, params: k.params, priv: k.priv, pub: k.pub}, nil
+}
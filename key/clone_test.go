@@ -0,0 +1,60 @@
+package key
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestClone(t *testing.T) {
+	RegisterTestingT(t)
+
+	forEachKey(func(alg string, k Key) {
+		k.Metadata().KeyOperations = []string{OperationSign, OperationVerify}
+
+		c, err := Clone(k)
+		Expect(err).To(BeNil(), alg)
+		Expect(c.ID()).To(Equal(k.ID()), alg)
+
+		out, _ := json.Marshal(k)
+		Expect(json.Marshal(c)).To(MatchJSON(out), alg)
+
+		// Independent metadata and private material
+		c.Metadata().KeyOperations[0] = OperationVerify
+		Expect(k.Metadata().KeyOperations[0]).To(Equal(OperationSign), alg)
+
+		Destroy(k)
+		Expect(c.HasPrivate()).To(BeTrue(), alg)
+		if CanSign(c) {
+			_, err = c.Sign([]byte("payload"))
+			Expect(err).To(BeNil(), alg)
+		}
+
+		pub, err := Clone(k.Public())
+		Expect(err).To(BeNil(), alg)
+		Expect(pub.HasPrivate()).To(BeFalse(), alg)
+	})
+}
+
+func TestClone_LockedMemory(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, _ := Ed25519()
+	err := LockMemory(k)
+	if err == ErrMemoryLockNotSupported {
+		t.Skip("memory locking not supported")
+	}
+	Expect(err).To(BeNil())
+
+	c, err := Clone(k)
+	Expect(err).To(BeNil())
+	Expect(c.(*ed25519Key).locked).To(BeTrue())
+
+	Destroy(k)
+	sig, err := c.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+	valid, _ := c.Public().Verify([]byte("payload"), sig)
+	Expect(valid).To(BeTrue())
+	Destroy(c)
+}
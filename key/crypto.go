@@ -0,0 +1,149 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rsa"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ed25519"
+)
+
+// cryptoKey is implemented by keys exposing their native key material
+type cryptoKey interface {
+	cryptoPrivateKey() crypto.PrivateKey
+	cryptoPublicKey() crypto.PublicKey
+}
+
+// fromCryptoPrivateKey wraps a native private key as a key of the given
+// algorithm (see Derive for the accepted values), the native key must be of
+// the kind expected by the algorithm
+func fromCryptoPrivateKey(priv crypto.PrivateKey, alg string) (Key, error) {
+	switch alg {
+	case "Ed25519":
+		if pk, ok := priv.(ed25519.PrivateKey); ok {
+			return &ed25519Key{attributes: newAttributes(), priv: pk, pub: pk.Public().(ed25519.PublicKey)}, nil
+		}
+	case "Ed448":
+		if pk, ok := priv.(ed448.PrivateKey); ok {
+			return &ed448Key{attributes: newAttributes(), priv: pk, pub: pk.Public().(ed448.PublicKey)}, nil
+		}
+	case "ES256K", "ES256K-R":
+		if pk, ok := priv.(*secp256k1.PrivateKey); ok {
+			return &secp256k1Key{attributes: newAttributes(), recoverable: alg == "ES256K-R", priv: pk, pub: pk.PubKey()}, nil
+		}
+	default:
+		if params, ok := ecdsaAlgorithms[alg]; ok {
+			if pk, ok := priv.(*ecdsa.PrivateKey); ok && pk.Curve.Params().Name == params.crv {
+				return &ecdsaKey{attributes: newAttributes(), params: params, priv: pk, pub: &pk.PublicKey}, nil
+			}
+			break
+		}
+		if params, ok := rsaAlgorithms[alg]; ok {
+			if pk, ok := priv.(*rsa.PrivateKey); ok {
+				return &rsaKey{attributes: newAttributes(), params: params, priv: pk, pub: &pk.PublicKey}, nil
+			}
+			break
+		}
+		if params, ok := ecdhCurves[alg]; ok {
+			pk, err := toECDHPrivateKey(priv, params)
+			if err != nil {
+				return nil, err
+			}
+			return &ecdhKey{attributes: newAttributes(), alg: defaultECDHAlgorithm, params: params, priv: pk, pub: pk.PublicKey()}, nil
+		}
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	return nil, ErrKeyAlgorithmMismatch
+}
+
+// fromCryptoPublicKey wraps a native public key as a key of the given
+// algorithm, see fromCryptoPrivateKey
+func fromCryptoPublicKey(pub crypto.PublicKey, alg string) (Key, error) {
+	switch alg {
+	case "Ed25519":
+		if pk, ok := pub.(ed25519.PublicKey); ok {
+			return &ed25519Key{pub: pk}, nil
+		}
+	case "Ed448":
+		if pk, ok := pub.(ed448.PublicKey); ok {
+			return &ed448Key{pub: pk}, nil
+		}
+	case "ES256K", "ES256K-R":
+		if pk, ok := pub.(*secp256k1.PublicKey); ok {
+			return &secp256k1Key{recoverable: alg == "ES256K-R", pub: pk}, nil
+		}
+	default:
+		if params, ok := ecdsaAlgorithms[alg]; ok {
+			if pk, ok := pub.(*ecdsa.PublicKey); ok && pk.Curve.Params().Name == params.crv {
+				return &ecdsaKey{params: params, pub: pk}, nil
+			}
+			break
+		}
+		if params, ok := rsaAlgorithms[alg]; ok {
+			if pk, ok := pub.(*rsa.PublicKey); ok {
+				return &rsaKey{params: params, pub: pk}, nil
+			}
+			break
+		}
+		if params, ok := ecdhCurves[alg]; ok {
+			pk, err := toECDHPublicKey(pub, params)
+			if err != nil {
+				return nil, err
+			}
+			return &ecdhKey{alg: defaultECDHAlgorithm, params: params, pub: pk}, nil
+		}
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	return nil, ErrKeyAlgorithmMismatch
+}
+
+// toECDHPrivateKey converts a native private key to a key agreement key of
+// the given curve, NIST curve keys are decoded as ECDSA keys
+func toECDHPrivateKey(priv crypto.PrivateKey, params *ecdhParams) (*ecdh.PrivateKey, error) {
+	var (
+		pk  *ecdh.PrivateKey
+		err error
+	)
+	switch k := priv.(type) {
+	case *ecdh.PrivateKey:
+		pk = k
+	case *ecdsa.PrivateKey:
+		if pk, err = k.ECDH(); err != nil {
+			return nil, ErrKeyAlgorithmMismatch
+		}
+	default:
+		return nil, ErrKeyAlgorithmMismatch
+	}
+
+	if pk.Curve() != params.curve {
+		return nil, ErrKeyAlgorithmMismatch
+	}
+	return pk, nil
+}
+
+func toECDHPublicKey(pub crypto.PublicKey, params *ecdhParams) (*ecdh.PublicKey, error) {
+	var (
+		pk  *ecdh.PublicKey
+		err error
+	)
+	switch k := pub.(type) {
+	case *ecdh.PublicKey:
+		pk = k
+	case *ecdsa.PublicKey:
+		if pk, err = k.ECDH(); err != nil {
+			return nil, ErrKeyAlgorithmMismatch
+		}
+	default:
+		return nil, ErrKeyAlgorithmMismatch
+	}
+
+	if pk.Curve() != params.curve {
+		return nil, ErrKeyAlgorithmMismatch
+	}
+	return pk, nil
+}
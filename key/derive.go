@@ -0,0 +1,174 @@
+package key
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/sha512"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+// MinSeedSize is the minimal size of a master seed used for key derivation
+const MinSeedSize = 32
+
+// derivationSalt separates the derivations of this package from other HKDF
+// usages of the same master seed
+var derivationSalt = []byte("go.zenithar.org/keystore/key/derive")
+
+// DeriveSeed derives the node seed of a "/" separated path (for example
+// "tenant/acme") from a master seed. Each path segment is derived from its
+// parent node using HKDF-SHA512, so that a node seed could be delegated to
+// derive its own subtree: Derive(DeriveSeed(seed, "tenant/acme"), "2026-10",
+// alg) is the key of Derive(seed, "tenant/acme/2026-10", alg).
+func DeriveSeed(seed []byte, path string) ([]byte, error) {
+	if len(seed) < MinSeedSize {
+		return nil, ErrInvalidSeed
+	}
+	if len(path) == 0 {
+		return nil, ErrInvalidDerivationPath
+	}
+
+	node := seed
+	for _, segment := range strings.Split(path, "/") {
+		if len(segment) == 0 {
+			return nil, ErrInvalidDerivationPath
+		}
+
+		next := make([]byte, sha512.Size)
+		if _, err := io.ReadFull(hkdf.New(sha512.New, node, derivationSalt, []byte("node\x00"+segment)), next); err != nil {
+			return nil, err
+		}
+		node = next
+	}
+
+	return node, nil
+}
+
+// Derive deterministically builds the key of the given path from a master
+// seed, the same seed, path and algorithm always give the same key.
+// alg is the algorithm of the key (Ed25519, Ed448, ES256, ES384, ES512,
+// ES256K, ES256K-R, HS256, HS384, HS512) or the curve of a key agreement key
+// (X25519, P-256, P-384, P-521). RSA keys could not be derived. Derived keys
+// have no creation date, so that their JWK is also deterministic, set the
+// IssuedAt metadata if needed.
+func Derive(seed []byte, path, alg string) (Key, error) {
+	node, err := DeriveSeed(seed, path)
+	if err != nil {
+		return nil, err
+	}
+
+	r := hkdf.New(sha512.New, node, derivationSalt, []byte("key\x00"+alg))
+
+	switch alg {
+	case "Ed25519":
+		s := make([]byte, ed25519.SeedSize)
+		if _, err := io.ReadFull(r, s); err != nil {
+			return nil, err
+		}
+		priv := ed25519.NewKeyFromSeed(s)
+		return &ed25519Key{priv: priv, pub: priv.Public().(ed25519.PublicKey)}, nil
+
+	case "Ed448":
+		s := make([]byte, ed448.SeedSize)
+		if _, err := io.ReadFull(r, s); err != nil {
+			return nil, err
+		}
+		priv := ed448.NewKeyFromSeed(s)
+		return &ed448Key{priv: priv, pub: priv.Public().(ed448.PublicKey)}, nil
+
+	case "ES256K", "ES256K-R":
+		priv, err := deriveSecp256k1(r)
+		if err != nil {
+			return nil, err
+		}
+		return &secp256k1Key{recoverable: alg == "ES256K-R", priv: priv, pub: priv.PubKey()}, nil
+	}
+
+	if params, ok := ecdsaAlgorithms[alg]; ok {
+		priv, err := deriveECDH(r, ecdhCurves[params.crv])
+		if err != nil {
+			return nil, err
+		}
+
+		// Uncompressed point encoding
+		point := priv.PublicKey().Bytes()
+		size := curveSize(params.curve)
+		pub := ecdsa.PublicKey{
+			Curve: params.curve,
+			X:     new(big.Int).SetBytes(point[1 : 1+size]),
+			Y:     new(big.Int).SetBytes(point[1+size:]),
+		}
+		return &ecdsaKey{
+			params: params,
+			priv:   &ecdsa.PrivateKey{PublicKey: pub, D: new(big.Int).SetBytes(priv.Bytes())},
+			pub:    &pub,
+		}, nil
+	}
+
+	if params, ok := hmacAlgorithms[alg]; ok {
+		secret := make([]byte, params.hash.Size())
+		if _, err := io.ReadFull(r, secret); err != nil {
+			return nil, err
+		}
+		return &hmacKey{params: params, secret: secret}, nil
+	}
+
+	if params, ok := ecdhCurves[alg]; ok {
+		priv, err := deriveECDH(r, params)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdhKey{alg: defaultECDHAlgorithm, params: params, priv: priv, pub: priv.PublicKey()}, nil
+	}
+
+	return nil, ErrAlgorithmNotSupported
+}
+
+// -----------------------------------------------------------------------------
+
+// maxDerivationAttempts bounds the rejection sampling of scalars, the
+// probability of rejecting a candidate is negligible for all curves
+const maxDerivationAttempts = 32
+
+// deriveECDH reads candidate scalars until one is in the curve range
+func deriveECDH(r io.Reader, params *ecdhParams) (*ecdh.PrivateKey, error) {
+	b := make([]byte, params.size)
+	for i := 0; i < maxDerivationAttempts; i++ {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+		if params.crv == "P-521" {
+			// Only keep the 521 bits of the scalar
+			b[0] &= 0x01
+		}
+
+		priv, err := params.curve.NewPrivateKey(b)
+		if err == nil {
+			return priv, nil
+		}
+	}
+
+	return nil, ErrDerivationFailed
+}
+
+func deriveSecp256k1(r io.Reader) (*secp256k1.PrivateKey, error) {
+	b := make([]byte, secp256k1CoordinateSize)
+	for i := 0; i < maxDerivationAttempts; i++ {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, err
+		}
+
+		var s secp256k1.ModNScalar
+		if overflow := s.SetByteSlice(b); !overflow && !s.IsZero() {
+			return secp256k1.NewPrivateKey(&s), nil
+		}
+	}
+
+	return nil, ErrDerivationFailed
+}
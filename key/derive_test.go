@@ -0,0 +1,91 @@
+package key
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+var derivationSeed = bytes.Repeat([]byte{0x42}, MinSeedSize)
+
+func TestDerive_Deterministic(t *testing.T) {
+	RegisterTestingT(t)
+
+	algorithms := []string{
+		"Ed25519", "Ed448", "ES256", "ES384", "ES512", "ES256K", "ES256K-R",
+		"HS256", "HS384", "HS512", "X25519", "P-256", "P-384", "P-521",
+	}
+
+	for _, alg := range algorithms {
+		k1, err := Derive(derivationSeed, "tenant/acme/2026-10", alg)
+		Expect(err).To(BeNil(), alg)
+		Expect(k1.HasPrivate()).To(BeTrue(), alg)
+
+		k2, err := Derive(derivationSeed, "tenant/acme/2026-10", alg)
+		Expect(err).To(BeNil(), alg)
+		Expect(k2.ID()).To(Equal(k1.ID()), alg)
+		Expect(k1.CreatedAt().IsZero()).To(BeTrue(), alg)
+
+		out1, err := json.Marshal(k1)
+		Expect(err).To(BeNil(), alg)
+		out2, err := json.Marshal(k2)
+		Expect(err).To(BeNil(), alg)
+		Expect(out2).To(Equal(out1), alg)
+
+		other, err := Derive(derivationSeed, "tenant/acme/2026-11", alg)
+		Expect(err).To(BeNil(), alg)
+		Expect(other.ID()).NotTo(Equal(k1.ID()), alg)
+
+		// Derived keys are usable
+		if _, ok := k1.(KeyAgreement); ok {
+			_, err = k1.(KeyAgreement).SharedSecret(other.Public())
+			Expect(err).To(BeNil(), alg)
+			continue
+		}
+
+		sig, err := k1.Sign([]byte("payload"))
+		Expect(err).To(BeNil(), alg)
+		valid, err := k2.Verify([]byte("payload"), sig)
+		Expect(err).To(BeNil(), alg)
+		Expect(valid).To(BeTrue(), alg)
+	}
+}
+
+func TestDerive_Hierarchy(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, err := Derive(derivationSeed, "tenant/acme/2026-10", "Ed25519")
+	Expect(err).To(BeNil())
+
+	// Known answer, derivation must never change
+	Expect(hex.EncodeToString(k.(*ed25519Key).priv.Seed())).To(Equal("e65b87ed002696670227fc6d8a85a2f2cd134058935194c167c64f3dccd3e741"))
+
+	node, err := DeriveSeed(derivationSeed, "tenant/acme")
+	Expect(err).To(BeNil())
+	delegated, err := Derive(node, "2026-10", "Ed25519")
+	Expect(err).To(BeNil())
+	Expect(delegated.ID()).To(Equal(k.ID()))
+
+	// Algorithms are separated
+	es, _ := Derive(derivationSeed, "tenant/acme/2026-10", "ES256K")
+	recoverable, _ := Derive(derivationSeed, "tenant/acme/2026-10", "ES256K-R")
+	Expect(es.(*secp256k1Key).priv.Key).NotTo(Equal(recoverable.(*secp256k1Key).priv.Key))
+}
+
+func TestDerive_Errors(t *testing.T) {
+	RegisterTestingT(t)
+
+	_, err := Derive(derivationSeed[:16], "tenant/acme", "Ed25519")
+	Expect(err).To(Equal(ErrInvalidSeed))
+
+	for _, path := range []string{"", "tenant//acme", "tenant/"} {
+		_, err = Derive(derivationSeed, path, "Ed25519")
+		Expect(err).To(Equal(ErrInvalidDerivationPath), path)
+	}
+
+	_, err = Derive(derivationSeed, "tenant/acme", "RS256")
+	Expect(err).To(Equal(ErrAlgorithmNotSupported))
+}
@@ -0,0 +1,182 @@
+package key
+
+import (
+	"math/big"
+	"runtime"
+)
+
+// Destroyer is implemented by keys able to wipe their private material. A
+// destroyed key behaves as its public part, and must not be used concurrently
+// with its destruction.
+type Destroyer interface {
+	Destroy()
+}
+
+// Destroy wipes the private material of the key. This is a best effort, copies
+// made outside of the key (serialization, native keys exposed by adapters)
+// or internal to the standard library are not reachable.
+func Destroy(k Key) {
+	if d, ok := k.(Destroyer); ok {
+		d.Destroy()
+	}
+}
+
+// memoryLocker is implemented by keys able to move their private material to
+// locked memory
+type memoryLocker interface {
+	lockMemory() error
+}
+
+// LockMemory moves the private material of the key to memory pages locked in
+// RAM (mlock) and, where supported, excluded from core dumps. The pages are
+// wiped and unlocked by Destroy, or when the key is garbage collected. Only
+// Ed25519, Ed448 and HMAC keys are supported.
+func LockMemory(k Key) error {
+	l, ok := k.(memoryLocker)
+	if !ok {
+		return ErrInvalidOperationNotSupported
+	}
+	return l.lockMemory()
+}
+
+// -----------------------------------------------------------------------------
+
+// wipe zeroes the buffer
+func wipe(b []byte) {
+	clear(b)
+	runtime.KeepAlive(b)
+}
+
+// wipeInt zeroes the words of a big integer
+func wipeInt(i *big.Int) {
+	if i == nil {
+		return
+	}
+	clear(i.Bits())
+	i.SetInt64(0)
+}
+
+// lockedCopy returns a locked memory copy of the buffer, and wipes the
+// original one
+func lockedCopy(b []byte) ([]byte, error) {
+	locked, err := allocLocked(len(b))
+	if err != nil {
+		return nil, err
+	}
+	copy(locked, b)
+	wipe(b)
+
+	return locked, nil
+}
+
+// release wipes the buffer, and frees it when allocated in locked memory
+func release(b []byte, locked bool) {
+	if locked {
+		freeLocked(b)
+		return
+	}
+	wipe(b)
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ed25519Key) Destroy() {
+	release(k.priv, k.locked)
+	k.priv, k.locked = nil, false
+}
+
+func (k *ed25519Key) lockMemory() error {
+	if !k.HasPrivate() || k.locked {
+		return nil
+	}
+
+	b, err := lockedCopy(k.priv)
+	if err != nil {
+		return err
+	}
+	k.priv, k.locked = b, true
+	runtime.SetFinalizer(k, (*ed25519Key).Destroy)
+
+	return nil
+}
+
+func (k *ed448Key) Destroy() {
+	release(k.priv, k.locked)
+	k.priv, k.locked = nil, false
+}
+
+func (k *ed448Key) lockMemory() error {
+	if !k.HasPrivate() || k.locked {
+		return nil
+	}
+
+	b, err := lockedCopy(k.priv)
+	if err != nil {
+		return err
+	}
+	k.priv, k.locked = b, true
+	runtime.SetFinalizer(k, (*ed448Key).Destroy)
+
+	return nil
+}
+
+func (k *hmacKey) Destroy() {
+	// Keep the identifier, it could not be computed without the secret
+	k.kid = k.ID()
+
+	release(k.secret, k.locked)
+	k.secret, k.locked = nil, false
+}
+
+func (k *hmacKey) lockMemory() error {
+	if !k.HasPrivate() || k.locked {
+		return nil
+	}
+
+	b, err := lockedCopy(k.secret)
+	if err != nil {
+		return err
+	}
+	k.secret, k.locked = b, true
+	runtime.SetFinalizer(k, (*hmacKey).Destroy)
+
+	return nil
+}
+
+func (k *ecdsaKey) Destroy() {
+	if k.priv != nil {
+		wipeInt(k.priv.D)
+	}
+	k.priv = nil
+}
+
+func (k *rsaKey) Destroy() {
+	if k.priv != nil {
+		wipeInt(k.priv.D)
+		for _, p := range k.priv.Primes {
+			wipeInt(p)
+		}
+		wipeInt(k.priv.Precomputed.Dp)
+		wipeInt(k.priv.Precomputed.Dq)
+		wipeInt(k.priv.Precomputed.Qinv)
+		for _, v := range k.priv.Precomputed.CRTValues {
+			wipeInt(v.Exp)
+			wipeInt(v.Coeff)
+			wipeInt(v.R)
+		}
+	}
+	k.priv = nil
+}
+
+func (k *secp256k1Key) Destroy() {
+	if k.priv != nil {
+		k.priv.Zero()
+	}
+	k.priv = nil
+}
+
+// The private scalar of crypto/ecdh keys is not reachable, only the reference
+// is dropped
+func (k *ecdhKey) Destroy() {
+	k.priv = nil
+}
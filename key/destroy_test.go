@@ -0,0 +1,100 @@
+package key
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestDestroy(t *testing.T) {
+	RegisterTestingT(t)
+
+	forEachKey(func(alg string, k Key) {
+		id := k.ID()
+		signs := CanSign(k)
+		wiped := privateMaterialWiped(k)
+
+		Destroy(k)
+		Expect(k.HasPrivate()).To(BeFalse(), alg)
+		Expect(k.ID()).To(Equal(id), alg)
+		Expect(wiped()).To(BeTrue(), alg)
+
+		_, err := k.Sign([]byte("payload"))
+		if signs {
+			Expect(err).To(Equal(ErrInvalidOperationCouldSignWithoutPrivateKey), alg)
+		} else {
+			Expect(err).ToNot(BeNil(), alg)
+		}
+
+		// Idempotent
+		Destroy(k)
+	})
+}
+
+// privateMaterialWiped keeps a reference on the private material of the key,
+// and returns a function checking it has been zeroed
+func privateMaterialWiped(k Key) func() bool {
+	zero := func(b []byte) bool {
+		return bytes.Equal(b, make([]byte, len(b)))
+	}
+
+	switch pk := k.(type) {
+	case *ed25519Key:
+		priv := pk.priv
+		return func() bool { return zero(priv) }
+	case *ed448Key:
+		priv := pk.priv
+		return func() bool { return zero(priv) }
+	case *hmacKey:
+		secret := pk.secret
+		return func() bool { return zero(secret) }
+	case *ecdsaKey:
+		d := pk.priv.D
+		return func() bool { return d.Sign() == 0 }
+	case *rsaKey:
+		priv := pk.priv
+		return func() bool {
+			for _, p := range priv.Primes {
+				if p.Sign() != 0 {
+					return false
+				}
+			}
+			return priv.D.Sign() == 0 && priv.Precomputed.Dp.Sign() == 0
+		}
+	case *secp256k1Key:
+		priv := pk.priv
+		return func() bool { return priv.Key.IsZero() }
+	}
+
+	// The private scalar of crypto/ecdh keys is not reachable
+	return func() bool { return true }
+}
+
+func TestLockMemory(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, generate := range []func() (Key, error){Ed25519, Ed448, HS256} {
+		k, _ := generate()
+		id := k.ID()
+
+		err := LockMemory(k)
+		if err == ErrMemoryLockNotSupported {
+			t.Skip("memory locking not supported")
+		}
+		Expect(err).To(BeNil())
+		Expect(k.ID()).To(Equal(id))
+
+		sig, err := k.Sign([]byte("payload"))
+		Expect(err).To(BeNil())
+		valid, err := k.Verify([]byte("payload"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeTrue())
+
+		Destroy(k)
+		Expect(k.HasPrivate()).To(BeFalse())
+	}
+
+	es, _ := ES256()
+	Expect(LockMemory(es)).To(Equal(ErrInvalidOperationNotSupported))
+}
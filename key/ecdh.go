@@ -0,0 +1,265 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ecdhParams describes a key agreement curve
+type ecdhParams struct {
+	crv   string
+	curve ecdh.Curve
+	okp   bool
+	size  int
+}
+
+var ecdhCurves = map[string]*ecdhParams{
+	"X25519": {crv: "X25519", curve: ecdh.X25519(), okp: true, size: 32},
+	"P-256":  {crv: "P-256", curve: ecdh.P256(), size: 32},
+	"P-384":  {crv: "P-384", curve: ecdh.P384(), size: 48},
+	"P-521":  {crv: "P-521", curve: ecdh.P521(), size: 66},
+}
+
+// defaultECDHAlgorithm is the JWE key management algorithm declared by generated keys
+const defaultECDHAlgorithm = "ECDH-ES"
+
+type ecdhKey struct {
+	attributes
+
+	alg    string
+	params *ecdhParams
+	priv   *ecdh.PrivateKey
+	pub    *ecdh.PublicKey
+}
+
+// X25519 key holder (ECDH-ES using Curve25519)
+func X25519() (Key, error) {
+	return generateECDH(ecdhCurves["X25519"])
+}
+
+// ECDHP256 key holder (ECDH-ES using P-256)
+func ECDHP256() (Key, error) {
+	return generateECDH(ecdhCurves["P-256"])
+}
+
+// ECDHP384 key holder (ECDH-ES using P-384)
+func ECDHP384() (Key, error) {
+	return generateECDH(ecdhCurves["P-384"])
+}
+
+// ECDHP521 key holder (ECDH-ES using P-521)
+func ECDHP521() (Key, error) {
+	return generateECDH(ecdhCurves["P-521"])
+}
+
+func generateECDH(params *ecdhParams) (Key, error) {
+	privateKey, err := params.curve.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdhKey{
+		attributes: newAttributes(),
+		alg:        defaultECDHAlgorithm,
+		params:     params,
+		priv:       privateKey,
+		pub:        privateKey.PublicKey(),
+	}, nil
+}
+
+// isECDHAlgorithm returns true for JWE ECDH-ES key management algorithms
+func isECDHAlgorithm(alg string) bool {
+	return strings.HasPrefix(alg, defaultECDHAlgorithm)
+}
+
+func toECDH(raw *rawJWK) (Key, error) {
+	params, ok := ecdhCurves[raw.Curve]
+	if !ok || params.okp != (raw.KeyType == "OKP") {
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	alg := raw.Algorithm
+	if len(alg) == 0 {
+		alg = defaultECDHAlgorithm
+	}
+	if !isECDHAlgorithm(alg) {
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	x, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != params.size {
+		return nil, errors.New("key: invalid ecdh public key size")
+	}
+
+	point := x
+	if !params.okp {
+		y, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.Y)
+		if err != nil {
+			return nil, err
+		}
+		if len(y) != params.size {
+			return nil, errors.New("key: invalid ecdh public key size")
+		}
+
+		// Uncompressed point encoding
+		point = append([]byte{4}, x...)
+		point = append(point, y...)
+	}
+
+	pub, err := params.curve.NewPublicKey(point)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &ecdhKey{
+		alg:    alg,
+		params: params,
+		pub:    pub,
+	}
+	if len(raw.D) > 0 {
+		d, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.D)
+		if err != nil {
+			return nil, err
+		}
+		priv, err := params.curve.NewPrivateKey(d)
+		if err != nil {
+			return nil, err
+		}
+		if !priv.PublicKey().Equal(pub) {
+			return nil, errors.New("key: ecdh private key does not match public key")
+		}
+		k.priv = priv
+	}
+
+	return k, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ecdhKey) Algorithm() string {
+	return k.alg
+}
+
+func (k *ecdhKey) ID() string {
+	if len(k.kid) > 0 {
+		return k.kid
+	}
+	return k.libtrustID()
+}
+
+func (k *ecdhKey) libtrustID() string {
+	return keyIDFromCryptoKey(k.pub)
+}
+
+func (k *ecdhKey) HasPrivate() bool {
+	return k.priv != nil
+}
+
+func (k *ecdhKey) HasPublic() bool {
+	return k.pub != nil
+}
+
+func (k *ecdhKey) Public() Key {
+	return &ecdhKey{
+		attributes: k.clone(),
+		alg:        k.alg,
+		params:     k.params,
+		pub:        k.pub,
+	}
+}
+
+func (k *ecdhKey) cryptoPrivateKey() crypto.PrivateKey {
+	if k.priv == nil {
+		return nil
+	}
+	return k.priv
+}
+
+func (k *ecdhKey) cryptoPublicKey() crypto.PublicKey {
+	return k.pub
+}
+
+// permits excludes signature operations, not supported by key agreement keys
+func (k *ecdhKey) permits(operation string) bool {
+	if operation == OperationSign || operation == OperationVerify {
+		return false
+	}
+	return k.attributes.permits(operation)
+}
+
+func (k *ecdhKey) Sign(data []byte) ([]byte, error) {
+	return nil, ErrInvalidOperationNotSupported
+}
+
+func (k *ecdhKey) Verify(data, sig []byte) (bool, error) {
+	return false, ErrInvalidOperationNotSupported
+}
+
+func (k *ecdhKey) SignWithOptions(data []byte, opts *SignOptions) ([]byte, error) {
+	return nil, ErrInvalidOperationNotSupported
+}
+
+func (k *ecdhKey) VerifyWithOptions(data, sig []byte, opts *SignOptions) (bool, error) {
+	return false, ErrInvalidOperationNotSupported
+}
+
+func (k *ecdhKey) SharedSecret(peer Key) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldAgreeWithoutPrivateKey
+	}
+	if !k.permits(OperationDeriveBits) {
+		return nil, operationNotPermitted(k, OperationDeriveBits)
+	}
+
+	p, ok := peer.(*ecdhKey)
+	if !ok || p.params != k.params || !p.HasPublic() {
+		return nil, ErrIncompatiblePeerKey
+	}
+
+	return k.priv.ECDH(p.pub)
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ecdhKey) MarshalJSON() ([]byte, error) {
+	r := k.raw()
+	r.KeyID = k.ID()
+	k.fill(r)
+
+	return json.Marshal(r)
+}
+
+func (k *ecdhKey) Thumbprint(h crypto.Hash) ([]byte, error) {
+	return thumbprint(k.raw(), h)
+}
+
+func (k *ecdhKey) raw() *rawJWK {
+	point := k.pub.Bytes()
+
+	r := &rawJWK{
+		Algorithm: k.Algorithm(),
+		Curve:     k.params.crv,
+	}
+	if k.params.okp {
+		r.KeyType = "OKP"
+		r.X = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(point)
+	} else {
+		// Skip the uncompressed point prefix
+		r.KeyType = "EC"
+		r.X = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(point[1 : 1+k.params.size])
+		r.Y = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(point[1+k.params.size:])
+	}
+	if k.HasPrivate() {
+		r.D = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.priv.Bytes())
+	}
+
+	return r
+}
@@ -0,0 +1,71 @@
+package key
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestECDH_Agreement(t *testing.T) {
+	RegisterTestingT(t)
+
+	for crv, generator := range map[string]func() (Key, error){
+		"X25519": X25519,
+		"P-256":  ECDHP256,
+		"P-384":  ECDHP384,
+		"P-521":  ECDHP521,
+	} {
+		alice, err := generator()
+		Expect(err).To(BeNil())
+		Expect(alice.Algorithm()).To(Equal("ECDH-ES"))
+		Expect(alice.HasPrivate()).To(BeTrue())
+
+		bob, err := generator()
+		Expect(err).To(BeNil())
+
+		// Exchange public keys through their JWK representation
+		out, err := json.Marshal(bob.Public())
+		Expect(err).To(BeNil())
+		Expect(string(out)).To(ContainSubstring(crv))
+		bobPublic, err := FromString(out)
+		Expect(err).To(BeNil())
+		Expect(bobPublic.ID()).To(Equal(bob.ID()))
+
+		s1, err := alice.(KeyAgreement).SharedSecret(bobPublic)
+		Expect(err).To(BeNil())
+		s2, err := bob.(KeyAgreement).SharedSecret(alice.Public())
+		Expect(err).To(BeNil())
+		Expect(s1).To(Equal(s2))
+
+		_, err = alice.Public().(KeyAgreement).SharedSecret(bob)
+		Expect(err).To(Equal(ErrInvalidOperationCouldAgreeWithoutPrivateKey))
+
+		_, err = alice.Sign([]byte("payload"))
+		Expect(err).To(Equal(ErrInvalidOperationNotSupported))
+	}
+}
+
+func TestECDH_Deserialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 8037 Appendix A.6 (RFC 7748 Section 6.1 vectors)
+	bob, err := FromString([]byte(`{"kty":"OKP","crv":"X25519","kid":"Bob","x":"3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"}`))
+	Expect(err).To(BeNil())
+	Expect(bob.HasPrivate()).To(BeFalse())
+
+	ephemeral, err := FromString([]byte(`{"kty":"OKP","crv":"X25519","x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo","d":"dwdtCnMYpX08FsFyUbJmRd9ML4frwJkqsXf7pR25LCo"}`))
+	Expect(err).To(BeNil())
+	Expect(ephemeral.HasPrivate()).To(BeTrue())
+
+	secret, err := ephemeral.(KeyAgreement).SharedSecret(bob)
+	Expect(err).To(BeNil())
+	Expect(hex.EncodeToString(secret)).To(Equal("4a5d9d5ba4ce2de1728e3bf480350f25e07e21c947d19e3376f09b3c1e161742"))
+
+	// Wrong curve
+	other, err := ECDHP256()
+	Expect(err).To(BeNil())
+	_, err = ephemeral.(KeyAgreement).SharedSecret(other)
+	Expect(err).To(Equal(ErrIncompatiblePeerKey))
+}
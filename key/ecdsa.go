@@ -0,0 +1,280 @@
+package key
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	// Register hash functions
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// ecdsaParams describes the curve and the hash used by an ECDSA JWS algorithm
+type ecdsaParams struct {
+	alg   string
+	curve elliptic.Curve
+	crv   string
+	hash  crypto.Hash
+}
+
+var ecdsaAlgorithms = map[string]*ecdsaParams{
+	"ES256": {alg: "ES256", curve: elliptic.P256(), crv: "P-256", hash: crypto.SHA256},
+	"ES384": {alg: "ES384", curve: elliptic.P384(), crv: "P-384", hash: crypto.SHA384},
+	"ES512": {alg: "ES512", curve: elliptic.P521(), crv: "P-521", hash: crypto.SHA512},
+}
+
+func ecdsaParamsFromCurve(crv string) *ecdsaParams {
+	for _, p := range ecdsaAlgorithms {
+		if p.crv == crv {
+			return p
+		}
+	}
+	return nil
+}
+
+type ecdsaKey struct {
+	attributes
+
+	params *ecdsaParams
+	priv   *ecdsa.PrivateKey
+	pub    *ecdsa.PublicKey
+}
+
+// ES256 key holder (ECDSA using P-256 and SHA-256)
+func ES256() (Key, error) {
+	return generateECDSA(ecdsaAlgorithms["ES256"])
+}
+
+// ES384 key holder (ECDSA using P-384 and SHA-384)
+func ES384() (Key, error) {
+	return generateECDSA(ecdsaAlgorithms["ES384"])
+}
+
+// ES512 key holder (ECDSA using P-521 and SHA-512)
+func ES512() (Key, error) {
+	return generateECDSA(ecdsaAlgorithms["ES512"])
+}
+
+func generateECDSA(params *ecdsaParams) (Key, error) {
+	privateKey, err := ecdsa.GenerateKey(params.curve, rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ecdsaKey{
+		attributes: newAttributes(),
+		params:     params,
+		priv:       privateKey,
+		pub:        &privateKey.PublicKey,
+	}, nil
+}
+
+func toECDSA(raw *rawJWK) (Key, error) {
+	params := ecdsaParamsFromCurve(raw.Curve)
+	if params == nil {
+		return nil, ErrAlgorithmNotSupported
+	}
+	if len(raw.Algorithm) > 0 && raw.Algorithm != params.alg {
+		return nil, errors.New("key: ecdsa algorithm does not match curve")
+	}
+
+	size := curveSize(params.curve)
+
+	x, err := decodeCoordinate(raw.X, size)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeCoordinate(raw.Y, size)
+	if err != nil {
+		return nil, err
+	}
+	if !params.curve.IsOnCurve(x, y) {
+		return nil, errors.New("key: invalid ecdsa public key, point is not on curve")
+	}
+
+	k := &ecdsaKey{
+		params: params,
+		pub: &ecdsa.PublicKey{
+			Curve: params.curve,
+			X:     x,
+			Y:     y,
+		},
+	}
+	if len(raw.D) > 0 {
+		d, err := decodeCoordinate(raw.D, size)
+		if err != nil {
+			return nil, err
+		}
+		if d.Sign() == 0 || d.Cmp(params.curve.Params().N) >= 0 {
+			return nil, errors.New("key: invalid ecdsa private key")
+		}
+		if px, py := params.curve.ScalarBaseMult(d.FillBytes(make([]byte, size))); px.Cmp(x) != 0 || py.Cmp(y) != 0 {
+			return nil, errors.New("key: ecdsa private key does not match public key")
+		}
+		k.priv = &ecdsa.PrivateKey{
+			PublicKey: *k.pub,
+			D:         d,
+		}
+	}
+
+	return k, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ecdsaKey) Algorithm() string {
+	return k.params.alg
+}
+
+func (k *ecdsaKey) ID() string {
+	if len(k.kid) > 0 {
+		return k.kid
+	}
+	return k.libtrustID()
+}
+
+func (k *ecdsaKey) libtrustID() string {
+	return keyIDFromCryptoKey(k.pub)
+}
+
+func (k *ecdsaKey) HasPrivate() bool {
+	return k.priv != nil
+}
+
+func (k *ecdsaKey) HasPublic() bool {
+	return k.pub != nil
+}
+
+func (k *ecdsaKey) Public() Key {
+	return &ecdsaKey{
+		attributes: k.clone(),
+		params:     k.params,
+		pub:        k.pub,
+	}
+}
+
+func (k *ecdsaKey) cryptoPrivateKey() crypto.PrivateKey {
+	if k.priv == nil {
+		return nil
+	}
+	return k.priv
+}
+
+func (k *ecdsaKey) cryptoPublicKey() crypto.PublicKey {
+	return k.pub
+}
+
+func (k *ecdsaKey) Sign(data []byte) ([]byte, error) {
+	return k.SignWithOptions(data, nil)
+}
+
+func (k *ecdsaKey) Verify(data, sig []byte) (bool, error) {
+	return k.VerifyWithOptions(data, sig, nil)
+}
+
+// SignWithOptions signs the message, or its digest when pre-hashed with the
+// algorithm hash function
+func (k *ecdsaKey) SignWithOptions(data []byte, opts *SignOptions) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+
+	digest, err := digestWithOptions(k.params.hash, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r, s, err := ecdsa.Sign(rand.Reader, k.priv, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// JWA 3.4: the signature is the concatenation of R and S as fixed size octets
+	size := curveSize(k.params.curve)
+	sig := make([]byte, 2*size)
+	r.FillBytes(sig[:size])
+	s.FillBytes(sig[size:])
+
+	return sig, nil
+}
+
+func (k *ecdsaKey) VerifyWithOptions(data, sig []byte, opts *SignOptions) (bool, error) {
+	if !k.HasPublic() {
+		return false, ErrInvalidOperationCouldVerifyWithoutPublicKey
+	}
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+
+	digest, err := digestWithOptions(k.params.hash, data, opts)
+	if err != nil {
+		return false, err
+	}
+
+	size := curveSize(k.params.curve)
+	if len(sig) != 2*size {
+		return false, nil
+	}
+
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+
+	return ecdsa.Verify(k.pub, digest, r, s), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ecdsaKey) MarshalJSON() ([]byte, error) {
+	r := k.raw()
+	r.KeyID = k.ID()
+	k.fill(r)
+
+	return json.Marshal(r)
+}
+
+func (k *ecdsaKey) Thumbprint(h crypto.Hash) ([]byte, error) {
+	return thumbprint(k.raw(), h)
+}
+
+func (k *ecdsaKey) raw() *rawJWK {
+	size := curveSize(k.params.curve)
+
+	r := &rawJWK{
+		KeyType:   "EC",
+		Algorithm: k.Algorithm(),
+		Curve:     k.params.crv,
+		X:         base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.pub.X.FillBytes(make([]byte, size))),
+		Y:         base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.pub.Y.FillBytes(make([]byte, size))),
+	}
+	if k.HasPrivate() {
+		r.D = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.priv.D.FillBytes(make([]byte, size)))
+	}
+
+	return r
+}
+
+// -----------------------------------------------------------------------------
+
+func curveSize(curve elliptic.Curve) int {
+	return (curve.Params().BitSize + 7) / 8
+}
+
+func decodeCoordinate(value string, size int) (*big.Int, error) {
+	b, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != size {
+		return nil, errors.New("key: invalid ecdsa coordinate size")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,105 @@
+package key
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestECDSA_Generation(t *testing.T) {
+	RegisterTestingT(t)
+
+	for alg, generator := range map[string]func() (Key, error){
+		"ES256": ES256,
+		"ES384": ES384,
+		"ES512": ES512,
+	} {
+		key, err := generator()
+		Expect(err).To(BeNil())
+		Expect(key.Algorithm()).To(Equal(alg))
+		Expect(key.HasPrivate()).To(BeTrue())
+		Expect(key.HasPublic()).To(BeTrue())
+
+		pub := key.Public()
+		Expect(pub.Algorithm()).To(Equal(alg))
+		Expect(pub.HasPrivate()).To(BeFalse())
+		Expect(pub.HasPublic()).To(BeTrue())
+		Expect(pub.ID()).To(Equal(key.ID()))
+
+		sig, err := key.Sign([]byte("payload"))
+		Expect(err).To(BeNil())
+
+		valid, err := pub.Verify([]byte("payload"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeTrue())
+
+		valid, err = pub.Verify([]byte("tampered"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeFalse())
+
+		_, err = pub.Sign([]byte("payload"))
+		Expect(err).To(Equal(ErrInvalidOperationCouldSignWithoutPrivateKey))
+	}
+}
+
+func TestECDSA_Deserialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 7515 Appendix A.3 key
+	key, err := FromString([]byte(`{
+		"kty": "EC",
+		"crv": "P-256",
+		"x": "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+		"y": "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+		"d": "jpsQnnGQmL-YBIffH1136cspYG6-0iY7X1fCE9-E9LI"
+	}`))
+	Expect(err).To(BeNil())
+	Expect(key).ToNot(BeNil())
+	Expect(key.Algorithm()).To(Equal("ES256"))
+	Expect(key.HasPrivate()).To(BeTrue())
+	Expect(key.HasPublic()).To(BeTrue())
+
+	payload := []byte("eyJhbGciOiJFUzI1NiJ9.eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ")
+	sig, _ := base64.RawURLEncoding.DecodeString("DtEhU3ljbEg8L38VWAfUAqOyKAM6-Xx-F4GawxaepmXFCgfTjDxw5djxLa8ISlSApmWQxfKTUJqPP3-Kg6NU1Q")
+	valid, err := key.Verify(payload, sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	// Round trip
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+
+	back, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(back.ID()).To(Equal(key.ID()))
+	Expect(back.HasPrivate()).To(BeTrue())
+
+	out, err = json.Marshal(key.Public())
+	Expect(err).To(BeNil())
+	Expect(string(out)).ToNot(ContainSubstring(`"d"`))
+}
+
+func TestECDSA_InvalidPrivateKey(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 7515 Appendix A.3 public key with another private scalar
+	_, err := FromString([]byte(`{
+		"kty": "EC",
+		"crv": "P-256",
+		"x": "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+		"y": "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+		"d": "jpsQnnGQmL-YBIffH1136cspYG6-0iY7X1fCE9-E9LM"
+	}`))
+	Expect(err).To(MatchError("key: ecdsa private key does not match public key"))
+
+	_, err = FromString([]byte(`{
+		"kty": "EC",
+		"crv": "P-256",
+		"x": "f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU",
+		"y": "x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0",
+		"d": "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"
+	}`))
+	Expect(err).To(MatchError("key: invalid ecdsa private key"))
+}
@@ -1,19 +1,21 @@
 package key
 
 import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
-	"time"
-
-	"golang.org/x/crypto/ed25519"
 )
 
 type ed25519Key struct {
-	timestamp time.Time
-	priv      ed25519.PrivateKey
-	pub       ed25519.PublicKey
+	attributes
+
+	locked bool
+	priv   ed25519.PrivateKey
+	pub    ed25519.PublicKey
 }
 
 // Ed25519 key holder
@@ -24,13 +26,20 @@ func Ed25519() (Key, error) {
 	}
 
 	return &ed25519Key{
-		timestamp: time.Now().UTC(),
-		priv:      privateKey,
-		pub:       publicKey,
+		attributes: newAttributes(),
+		priv:       privateKey,
+		pub:        publicKey,
 	}, nil
 }
 
 func toEd25519(raw *rawJWK) (Key, error) {
+	// "EC" is the legacy algorithm emitted by previous versions
+	switch raw.Algorithm {
+	case "", "EdDSA", "EC":
+	default:
+		return nil, ErrAlgorithmNotSupported
+	}
+
 	x, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.X)
 	if err != nil {
 		return nil, err
@@ -48,10 +57,20 @@ func toEd25519(raw *rawJWK) (Key, error) {
 		if err != nil {
 			return nil, err
 		}
-		if len(d) != ed25519.PrivateKeySize {
+
+		// RFC 8037 uses the 32-byte seed, previous versions used the 64-byte
+		// expanded private key
+		switch len(d) {
+		case ed25519.SeedSize:
+			k.priv = ed25519.NewKeyFromSeed(d)
+		case ed25519.PrivateKeySize:
+			k.priv = d
+		default:
 			return nil, errors.New("key: invalid ed25519 private key size")
 		}
-		k.priv = d
+		if !bytes.Equal(k.priv.Public().(ed25519.PublicKey), k.pub) {
+			return nil, errors.New("key: ed25519 private key does not match public key")
+		}
 	}
 
 	return k, nil
@@ -64,6 +83,13 @@ func (k *ed25519Key) Algorithm() string {
 }
 
 func (k *ed25519Key) ID() string {
+	if len(k.kid) > 0 {
+		return k.kid
+	}
+	return k.libtrustID()
+}
+
+func (k *ed25519Key) libtrustID() string {
 	return keyIDFromData(k.pub)
 }
 
@@ -77,37 +103,109 @@ func (k *ed25519Key) HasPublic() bool {
 
 func (k *ed25519Key) Public() Key {
 	return &ed25519Key{
-		pub: k.pub,
+		attributes: k.clone(),
+		pub:        k.pub,
+	}
+}
+
+func (k *ed25519Key) cryptoPrivateKey() crypto.PrivateKey {
+	if len(k.priv) == 0 {
+		return nil
 	}
+	return k.priv
+}
+
+func (k *ed25519Key) cryptoPublicKey() crypto.PublicKey {
+	return k.pub
 }
 
 func (k *ed25519Key) Sign(data []byte) ([]byte, error) {
+	return k.SignWithOptions(data, nil)
+}
+
+func (k *ed25519Key) Verify(data, sig []byte) (bool, error) {
+	return k.VerifyWithOptions(data, sig, nil)
+}
+
+// SignWithOptions produces Ed25519, Ed25519ctx (context only) or Ed25519ph
+// (SHA-512 pre-hashed) signatures
+func (k *ed25519Key) SignWithOptions(data []byte, opts *SignOptions) ([]byte, error) {
 	if !k.HasPrivate() {
 		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
 	}
-	return ed25519.Sign(k.priv, data), nil
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+	if opts.isZero() {
+		return ed25519.Sign(k.priv, data), nil
+	}
+
+	o, err := ed25519Options(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	return k.priv.Sign(nil, data, o)
 }
 
-func (k *ed25519Key) Verify(data, sig []byte) (bool, error) {
+func (k *ed25519Key) VerifyWithOptions(data, sig []byte, opts *SignOptions) (bool, error) {
 	if !k.HasPublic() {
 		return false, ErrInvalidOperationCouldSignWithoutPrivateKey
 	}
-	return ed25519.Verify(k.pub, data, sig), nil
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+	if opts.isZero() {
+		return ed25519.Verify(k.pub, data, sig), nil
+	}
+
+	o, err := ed25519Options(data, opts)
+	if err != nil {
+		return false, err
+	}
+	return ed25519.VerifyWithOptions(k.pub, data, sig, o) == nil, nil
+}
+
+func ed25519Options(data []byte, opts *SignOptions) (*ed25519.Options, error) {
+	switch opts.Hash {
+	case crypto.Hash(0):
+	case crypto.SHA512:
+		if len(data) != crypto.SHA512.Size() {
+			return nil, ErrInvalidDigestSize
+		}
+	default:
+		return nil, ErrInvalidOperationNotSupported
+	}
+	if len(opts.Context) > maxContextSize {
+		return nil, ErrInvalidContext
+	}
+
+	return &ed25519.Options{Hash: opts.Hash, Context: opts.Context}, nil
 }
 
 // -----------------------------------------------------------------------------
 
 func (k *ed25519Key) MarshalJSON() ([]byte, error) {
+	r := k.raw()
+	r.KeyID = k.ID()
+	k.fill(r)
+
+	return json.Marshal(r)
+}
+
+func (k *ed25519Key) Thumbprint(h crypto.Hash) ([]byte, error) {
+	return thumbprint(k.raw(), h)
+}
+
+func (k *ed25519Key) raw() *rawJWK {
 	r := &rawJWK{
-		KeyID:     k.ID(),
 		KeyType:   "OKP",
-		Algorithm: "EC",
+		Algorithm: "EdDSA",
 		Curve:     k.Algorithm(),
 		X:         base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.pub),
 	}
 	if k.HasPrivate() {
-		r.D = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.priv)
+		r.D = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.priv.Seed())
 	}
 
-	return json.Marshal(r)
+	return r
 }
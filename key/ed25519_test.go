@@ -1,6 +1,8 @@
 package key
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -45,3 +47,41 @@ func TestEd25519_Deserialization(t *testing.T) {
 	Expect(key.HasPrivate()).To(BeTrue())
 	Expect(key.HasPublic()).To(BeTrue())
 }
+
+func TestEd25519_RFC8037(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 8037 Appendix A.1 key
+	key, err := FromString([]byte(`{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"d": "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+		"x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	}`))
+	Expect(err).To(BeNil())
+	Expect(key.HasPrivate()).To(BeTrue())
+
+	// RFC 8037 Appendix A.4 signature
+	sig, err := key.Sign([]byte("eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc"))
+	Expect(err).To(BeNil())
+	Expect(base64.RawURLEncoding.EncodeToString(sig)).To(Equal("hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"))
+
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+
+	var fields map[string]interface{}
+	Expect(json.Unmarshal(out, &fields)).To(BeNil())
+	Expect(fields["kty"]).To(Equal("OKP"))
+	Expect(fields["alg"]).To(Equal("EdDSA"))
+	Expect(fields["crv"]).To(Equal("Ed25519"))
+	Expect(fields["d"]).To(Equal("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A"))
+
+	// Mismatching private key
+	_, err = FromString([]byte(`{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"d": "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+		"x": "KuCra6cYFp3C4FcR4Yr6lC2gojKpS3d7wkazdKD_Dm4"
+	}`))
+	Expect(err).ToNot(BeNil())
+}
@@ -0,0 +1,188 @@
+package key
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudflare/circl/sign/ed448"
+)
+
+type ed448Key struct {
+	attributes
+
+	locked bool
+	priv   ed448.PrivateKey
+	pub    ed448.PublicKey
+}
+
+// Ed448 key holder
+func Ed448() (Key, error) {
+	publicKey, privateKey, err := ed448.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ed448Key{
+		attributes: newAttributes(),
+		priv:       privateKey,
+		pub:        publicKey,
+	}, nil
+}
+
+func toEd448(raw *rawJWK) (Key, error) {
+	x, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.X)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(x) != ed448.PublicKeySize {
+		return nil, errors.New("key: invalid ed448 public key size")
+	}
+
+	k := &ed448Key{
+		pub: x,
+	}
+	if len(raw.D) > 0 {
+		d, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.D)
+		if err != nil {
+			return nil, err
+		}
+		if len(d) != ed448.SeedSize {
+			return nil, errors.New("key: invalid ed448 private key size")
+		}
+		k.priv = ed448.NewKeyFromSeed(d)
+		if !k.priv.Public().(ed448.PublicKey).Equal(k.pub) {
+			return nil, errors.New("key: ed448 private key does not match public key")
+		}
+	}
+
+	return k, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ed448Key) Algorithm() string {
+	return "Ed448"
+}
+
+func (k *ed448Key) ID() string {
+	if len(k.kid) > 0 {
+		return k.kid
+	}
+	return k.libtrustID()
+}
+
+func (k *ed448Key) libtrustID() string {
+	return keyIDFromData(k.pub)
+}
+
+func (k *ed448Key) HasPrivate() bool {
+	return len(k.priv) > 0
+}
+
+func (k *ed448Key) HasPublic() bool {
+	return len(k.pub) > 0
+}
+
+func (k *ed448Key) Public() Key {
+	return &ed448Key{
+		attributes: k.clone(),
+		pub:        k.pub,
+	}
+}
+
+func (k *ed448Key) cryptoPrivateKey() crypto.PrivateKey {
+	if len(k.priv) == 0 {
+		return nil
+	}
+	return k.priv
+}
+
+func (k *ed448Key) cryptoPublicKey() crypto.PublicKey {
+	return k.pub
+}
+
+func (k *ed448Key) Sign(data []byte) ([]byte, error) {
+	return k.SignWithOptions(data, nil)
+}
+
+func (k *ed448Key) Verify(data, sig []byte) (bool, error) {
+	return k.VerifyWithOptions(data, sig, nil)
+}
+
+// SignWithOptions produces Ed448 signatures with an optional context
+func (k *ed448Key) SignWithOptions(data []byte, opts *SignOptions) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+
+	ctx, err := ed448Context(opts)
+	if err != nil {
+		return nil, err
+	}
+	return ed448.Sign(k.priv, data, ctx), nil
+}
+
+func (k *ed448Key) VerifyWithOptions(data, sig []byte, opts *SignOptions) (bool, error) {
+	if !k.HasPublic() {
+		return false, ErrInvalidOperationCouldVerifyWithoutPublicKey
+	}
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+
+	ctx, err := ed448Context(opts)
+	if err != nil {
+		return false, err
+	}
+	return ed448.Verify(k.pub, data, sig, ctx), nil
+}
+
+// ed448Context returns the signature context, Ed448ph is not supported as
+// it requires the whole message
+func ed448Context(opts *SignOptions) (string, error) {
+	if opts == nil {
+		return "", nil
+	}
+	if opts.Hash != 0 {
+		return "", ErrInvalidOperationNotSupported
+	}
+	if len(opts.Context) > maxContextSize {
+		return "", ErrInvalidContext
+	}
+	return opts.Context, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *ed448Key) MarshalJSON() ([]byte, error) {
+	r := k.raw()
+	r.KeyID = k.ID()
+	k.fill(r)
+
+	return json.Marshal(r)
+}
+
+func (k *ed448Key) Thumbprint(h crypto.Hash) ([]byte, error) {
+	return thumbprint(k.raw(), h)
+}
+
+func (k *ed448Key) raw() *rawJWK {
+	r := &rawJWK{
+		KeyType:   "OKP",
+		Algorithm: "EdDSA",
+		Curve:     k.Algorithm(),
+		X:         base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.pub),
+	}
+	if k.HasPrivate() {
+		r.D = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.priv.Seed())
+	}
+
+	return r
+}
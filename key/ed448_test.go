@@ -0,0 +1,55 @@
+package key
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestEd448_Generation(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, _ := Ed448()
+	Expect(key.Algorithm()).To(Equal("Ed448"))
+	Expect(key.HasPrivate()).To(BeTrue())
+	Expect(key.HasPublic()).To(BeTrue())
+
+	pub := key.Public()
+	Expect(pub.Algorithm()).To(Equal("Ed448"))
+	Expect(pub.HasPrivate()).To(BeFalse())
+	Expect(pub.HasPublic()).To(BeTrue())
+
+	sig, err := key.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+
+	valid, err := pub.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	valid, err = pub.Verify([]byte("tampered"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+}
+
+func TestEd448_Serialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, _ := Ed448()
+
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+	Expect(string(out)).To(ContainSubstring(`"crv":"Ed448"`))
+
+	back, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(back.Algorithm()).To(Equal("Ed448"))
+	Expect(back.ID()).To(Equal(key.ID()))
+	Expect(back.HasPrivate()).To(BeTrue())
+
+	sig, err := back.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+	valid, err := key.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+}
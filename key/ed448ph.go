@@ -0,0 +1,145 @@
+package key
+
+import (
+	"bytes"
+
+	"github.com/cloudflare/circl/ecc/goldilocks"
+	"github.com/cloudflare/circl/sign/ed448"
+	"golang.org/x/crypto/sha3"
+)
+
+// Ed448ph (RFC 8032 - 5.2) over a SHAKE256 message digest computed by the
+// caller, as circl only exposes Ed448ph over the whole message.
+
+const (
+	ed448ParamB     = 57
+	ed448HashSize   = 2 * ed448ParamB
+	ed448DigestSize = 64
+)
+
+// newEd448phHash returns the PH function of Ed448ph, SHAKE256 with a 64 bytes
+// output
+func newEd448phHash() *shakeHash {
+	return &shakeHash{ShakeHash: sha3.NewShake256(), size: ed448DigestSize}
+}
+
+func ed448SignPh(priv ed448.PrivateKey, digest []byte, ctx string) ([]byte, error) {
+	H := sha3.NewShake256()
+
+	// Secret scalar and prefix from the seed
+	var h [ed448HashSize]byte
+	H.Write(priv[:ed448.SeedSize])
+	H.Read(h[:])
+	h[0] &= 0xFC
+	h[ed448ParamB-1] = 0x00
+	h[ed448ParamB-2] |= 0x80
+	s := &goldilocks.Scalar{}
+	s.FromBytes(h[:ed448ParamB])
+	prefix := h[ed448ParamB:]
+
+	// r = SHAKE256(dom4(1, ctx) || prefix || PH(M), 114)
+	var rPM [ed448HashSize]byte
+	H.Reset()
+	ed448WriteDom(H, ctx)
+	H.Write(prefix)
+	H.Write(digest)
+	H.Read(rPM[:])
+
+	r := &goldilocks.Scalar{}
+	r.FromBytes(rPM[:])
+	R := make([]byte, ed448ParamB)
+	if err := (goldilocks.Curve{}).ScalarBaseMult(r).ToBytes(R); err != nil {
+		return nil, err
+	}
+
+	// k = SHAKE256(dom4(1, ctx) || R || A || PH(M), 114)
+	var hRAM [ed448HashSize]byte
+	H.Reset()
+	ed448WriteDom(H, ctx)
+	H.Write(R)
+	H.Write(priv[ed448.SeedSize:])
+	H.Write(digest)
+	H.Read(hRAM[:])
+
+	k := &goldilocks.Scalar{}
+	k.FromBytes(hRAM[:])
+	S := &goldilocks.Scalar{}
+	S.Mul(k, s)
+	S.Add(S, r)
+
+	sig := make([]byte, ed448.SignatureSize)
+	copy(sig[:ed448ParamB], R)
+	copy(sig[ed448ParamB:], S[:])
+
+	return sig, nil
+}
+
+func ed448VerifyPh(pub ed448.PublicKey, digest, sig []byte, ctx string) bool {
+	if len(pub) != ed448.PublicKeySize || len(sig) != ed448.SignatureSize || !ed448IsLessThanOrder(sig[ed448ParamB:]) {
+		return false
+	}
+
+	A, err := goldilocks.FromBytes(pub)
+	if err != nil {
+		return false
+	}
+
+	R := sig[:ed448ParamB]
+
+	var hRAM [ed448HashSize]byte
+	H := sha3.NewShake256()
+	ed448WriteDom(H, ctx)
+	H.Write(R)
+	H.Write(pub)
+	H.Write(digest)
+	H.Read(hRAM[:])
+
+	k := &goldilocks.Scalar{}
+	k.FromBytes(hRAM[:])
+	S := &goldilocks.Scalar{}
+	S.FromBytes(sig[ed448ParamB:])
+
+	// [S]B - [k]A must be equal to R
+	encR := make([]byte, ed448ParamB)
+	A.Neg()
+	if err := (goldilocks.Curve{}).CombinedMult(S, k, A).ToBytes(encR); err != nil {
+		return false
+	}
+
+	return bytes.Equal(R, encR)
+}
+
+// ed448WriteDom writes dom4(1, ctx), the pre-hash domain separator
+func ed448WriteDom(H sha3.ShakeHash, ctx string) {
+	H.Write([]byte("SigEd448"))
+	H.Write([]byte{0x01, byte(len(ctx))})
+	H.Write([]byte(ctx))
+}
+
+// ed448IsLessThanOrder checks that the encoded scalar is canonical
+func ed448IsLessThanOrder(x []byte) bool {
+	order := goldilocks.Curve{}.Order()
+	i := len(order) - 1
+	for i > 0 && x[i] == order[i] {
+		i--
+	}
+	return x[ed448ParamB-1] == 0 && x[i] < order[i]
+}
+
+// -----------------------------------------------------------------------------
+
+// shakeHash adapts a SHAKE function with a fixed output size to hash.Hash
+type shakeHash struct {
+	sha3.ShakeHash
+	size int
+}
+
+func (h *shakeHash) Sum(b []byte) []byte {
+	out := make([]byte, h.size)
+	h.ShakeHash.Clone().Read(out)
+	return append(b, out...)
+}
+
+func (h *shakeHash) Size() int {
+	return h.size
+}
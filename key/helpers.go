@@ -2,6 +2,8 @@ package key
 
 import (
 	"bytes"
+	"crypto"
+	"crypto/x509"
 	"encoding/base32"
 	"strings"
 
@@ -28,3 +30,11 @@ func keyIDFromData(data []byte) string {
 	h := hasher.Sum(nil)[:30]
 	return keyIDEncode(h)
 }
+
+func keyIDFromCryptoKey(pubKey crypto.PublicKey) string {
+	derBytes, err := x509.MarshalPKIXPublicKey(pubKey)
+	if err != nil {
+		return ""
+	}
+	return keyIDFromData(derBytes)
+}
@@ -0,0 +1,210 @@
+package key
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	// Register hash functions
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// hmacParams describes the hash used by an HMAC JWS algorithm
+type hmacParams struct {
+	alg  string
+	hash crypto.Hash
+}
+
+var hmacAlgorithms = map[string]*hmacParams{
+	"HS256": {alg: "HS256", hash: crypto.SHA256},
+	"HS384": {alg: "HS384", hash: crypto.SHA384},
+	"HS512": {alg: "HS512", hash: crypto.SHA512},
+}
+
+// hmacKey holds a symmetric secret. As there is no public part, the key
+// returned by Public() only keeps the identifier and never exports the secret.
+type hmacKey struct {
+	attributes
+
+	locked bool
+	params *hmacParams
+	secret []byte
+}
+
+// HS256 key holder (HMAC using SHA-256)
+func HS256() (Key, error) {
+	return generateHMAC(hmacAlgorithms["HS256"])
+}
+
+// HS384 key holder (HMAC using SHA-384)
+func HS384() (Key, error) {
+	return generateHMAC(hmacAlgorithms["HS384"])
+}
+
+// HS512 key holder (HMAC using SHA-512)
+func HS512() (Key, error) {
+	return generateHMAC(hmacAlgorithms["HS512"])
+}
+
+func generateHMAC(params *hmacParams) (Key, error) {
+	// JWA 3.2: a key of the same size as the hash output must be used
+	secret := make([]byte, params.hash.Size())
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+
+	return &hmacKey{
+		attributes: newAttributes(),
+		params:     params,
+		secret:     secret,
+	}, nil
+}
+
+func toHMAC(raw *rawJWK) (Key, error) {
+	alg := raw.Algorithm
+	if len(alg) == 0 {
+		alg = "HS256"
+	}
+	params, ok := hmacAlgorithms[alg]
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	// Secret-less representation, only keep the identifier
+	if len(raw.K) == 0 {
+		if len(raw.KeyID) == 0 {
+			return nil, errors.New("key: symmetric key without secret must have an identifier")
+		}
+		return &hmacKey{
+			attributes: attributes{kid: raw.KeyID},
+			params:     params,
+		}, nil
+	}
+
+	secret, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.K)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) < params.hash.Size() {
+		return nil, errors.New("key: invalid hmac secret size")
+	}
+
+	return &hmacKey{
+		params: params,
+		secret: secret,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *hmacKey) Algorithm() string {
+	return k.params.alg
+}
+
+func (k *hmacKey) ID() string {
+	if len(k.kid) > 0 {
+		return k.kid
+	}
+	return k.libtrustID()
+}
+
+func (k *hmacKey) libtrustID() string {
+	if !k.HasPrivate() {
+		return k.kid
+	}
+	return keyIDFromData(k.secret)
+}
+
+func (k *hmacKey) HasPrivate() bool {
+	return len(k.secret) > 0
+}
+
+func (k *hmacKey) HasPublic() bool {
+	return false
+}
+
+func (k *hmacKey) Public() Key {
+	// Keep the identifier, it could not be computed without the secret
+	attrs := k.clone()
+	attrs.kid = k.ID()
+
+	return &hmacKey{
+		attributes: attrs,
+		params:     k.params,
+	}
+}
+
+func (k *hmacKey) Sign(data []byte) ([]byte, error) {
+	return k.SignWithOptions(data, nil)
+}
+
+func (k *hmacKey) Verify(data, sig []byte) (bool, error) {
+	return k.VerifyWithOptions(data, sig, nil)
+}
+
+// SignWithOptions only accepts empty options, a MAC could not be computed
+// from a digest
+func (k *hmacKey) SignWithOptions(data []byte, opts *SignOptions) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+	if !opts.isZero() {
+		return nil, ErrInvalidOperationNotSupported
+	}
+
+	mac := hmac.New(k.params.hash.New, k.secret)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+func (k *hmacKey) VerifyWithOptions(data, sig []byte, opts *SignOptions) (bool, error) {
+	if !k.HasPrivate() {
+		return false, ErrInvalidOperationCouldVerifyWithoutSecretKey
+	}
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+	if !opts.isZero() {
+		return false, ErrInvalidOperationNotSupported
+	}
+
+	mac := hmac.New(k.params.hash.New, k.secret)
+	mac.Write(data)
+	return hmac.Equal(mac.Sum(nil), sig), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *hmacKey) MarshalJSON() ([]byte, error) {
+	r := k.raw()
+	r.KeyID = k.ID()
+	k.fill(r)
+
+	return json.Marshal(r)
+}
+
+func (k *hmacKey) Thumbprint(h crypto.Hash) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationThumbprintWithoutSecretKey
+	}
+	return thumbprint(k.raw(), h)
+}
+
+func (k *hmacKey) raw() *rawJWK {
+	r := &rawJWK{
+		KeyType:   "oct",
+		Algorithm: k.Algorithm(),
+	}
+	if k.HasPrivate() {
+		r.K = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.secret)
+	}
+
+	return r
+}
@@ -0,0 +1,80 @@
+package key
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestHMAC_Generation(t *testing.T) {
+	RegisterTestingT(t)
+
+	for alg, generator := range map[string]func() (Key, error){
+		"HS256": HS256,
+		"HS384": HS384,
+		"HS512": HS512,
+	} {
+		key, err := generator()
+		Expect(err).To(BeNil())
+		Expect(key.Algorithm()).To(Equal(alg))
+		Expect(key.HasPrivate()).To(BeTrue())
+		Expect(key.HasPublic()).To(BeFalse())
+
+		sig, err := key.Sign([]byte("payload"))
+		Expect(err).To(BeNil())
+
+		valid, err := key.Verify([]byte("payload"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeTrue())
+
+		valid, err = key.Verify([]byte("tampered"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeFalse())
+
+		pub := key.Public()
+		Expect(pub.ID()).To(Equal(key.ID()))
+		Expect(pub.HasPrivate()).To(BeFalse())
+		Expect(pub.HasPublic()).To(BeFalse())
+
+		_, err = pub.Sign([]byte("payload"))
+		Expect(err).To(Equal(ErrInvalidOperationCouldSignWithoutPrivateKey))
+		_, err = pub.Verify([]byte("payload"), sig)
+		Expect(err).To(Equal(ErrInvalidOperationCouldVerifyWithoutSecretKey))
+	}
+}
+
+func TestHMAC_Serialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 7515 Appendix A.1 key
+	key, err := FromString([]byte(`{
+		"kty": "oct",
+		"k": "AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"
+	}`))
+	Expect(err).To(BeNil())
+	Expect(key.Algorithm()).To(Equal("HS256"))
+
+	payload := []byte("eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9.eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ")
+	sig, err := key.Sign(payload)
+	Expect(err).To(BeNil())
+	Expect(base64.RawURLEncoding.EncodeToString(sig)).To(Equal("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+	back, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(back.ID()).To(Equal(key.ID()))
+	Expect(back.HasPrivate()).To(BeTrue())
+
+	// Public representation never exports the secret
+	out, err = json.Marshal(key.Public())
+	Expect(err).To(BeNil())
+	Expect(string(out)).ToNot(ContainSubstring(`"k"`))
+
+	pub, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(pub.ID()).To(Equal(key.ID()))
+	Expect(pub.HasPrivate()).To(BeFalse())
+}
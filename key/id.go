@@ -0,0 +1,79 @@
+package key
+
+import (
+	"crypto"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// IDStrategy computes the identifier assigned to a key
+type IDStrategy func(Key) (string, error)
+
+// identifiable is implemented by keys accepting an assigned identifier
+type identifiable interface {
+	setID(string)
+	libtrustID() string
+}
+
+// LibtrustID is the default strategy, a libtrust-style SHA3 base32 fingerprint
+// of the public key (ABCD:EFGH:...)
+func LibtrustID(k Key) (string, error) {
+	i, ok := k.(identifiable)
+	if !ok {
+		return "", ErrAlgorithmNotSupported
+	}
+	return i.libtrustID(), nil
+}
+
+// ThumbprintID returns a strategy using the base64url encoded RFC 7638 JWK
+// thumbprint computed with the given hash
+func ThumbprintID(h crypto.Hash) IDStrategy {
+	return func(k Key) (string, error) {
+		t, err := k.Thumbprint(h)
+		if err != nil {
+			return "", err
+		}
+		return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(t), nil
+	}
+}
+
+// RandomID is a strategy assigning a random UUID (version 4)
+func RandomID(k Key) (string, error) {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		return "", err
+	}
+	u[6] = (u[6] & 0x0f) | 0x40 // Version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // Variant RFC 4122
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
+
+// StaticID returns a strategy assigning the given caller-supplied identifier.
+// Every key gets the same identifier, it is meant for a one-off AssignID call
+// rather than as the strategy of a keystore generating several keys.
+func StaticID(id string) IDStrategy {
+	return func(Key) (string, error) {
+		return id, nil
+	}
+}
+
+// AssignID computes the key identifier using the given strategy and assigns
+// it to the key
+func AssignID(k Key, strategy IDStrategy) error {
+	i, ok := k.(identifiable)
+	if !ok {
+		return ErrAlgorithmNotSupported
+	}
+
+	id, err := strategy(k)
+	if err != nil {
+		return err
+	}
+	if len(id) == 0 {
+		return ErrInvalidKeyID
+	}
+
+	i.setID(id)
+	return nil
+}
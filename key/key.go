@@ -1,6 +1,10 @@
 package key
 
-import "errors"
+import (
+	"crypto"
+	"errors"
+	"time"
+)
 
 // Key contract for key information holder
 type Key interface {
@@ -11,6 +15,26 @@ type Key interface {
 	Public() Key
 	Sign([]byte) ([]byte, error)
 	Verify([]byte, []byte) (bool, error)
+	SignWithOptions([]byte, *SignOptions) ([]byte, error)
+	VerifyWithOptions([]byte, []byte, *SignOptions) (bool, error)
+	Thumbprint(crypto.Hash) ([]byte, error)
+	Metadata() *Metadata
+	CreatedAt() time.Time
+}
+
+// KeyAgreement contract for keys able to compute a shared secret with a peer
+type KeyAgreement interface {
+	SharedSecret(peer Key) ([]byte, error)
+}
+
+// Age returns the time elapsed since the key creation, zero when the creation
+// date is unknown
+func Age(k Key) time.Duration {
+	created := k.CreatedAt()
+	if created.IsZero() {
+		return 0
+	}
+	return time.Since(created)
 }
 
 // -----------------------------------------------------------------------------
@@ -19,5 +43,25 @@ var (
 	ErrInvalidSignature                            = errors.New("key: invalid signature")
 	ErrInvalidOperationCouldSignWithoutPrivateKey  = errors.New("key: invalid operation : could not sign without a private key")
 	ErrInvalidOperationCouldVerifyWithoutPublicKey = errors.New("key: invalid operation : could not verify without a public key")
+	ErrInvalidOperationCouldVerifyWithoutSecretKey = errors.New("key: invalid operation : could not verify without a secret key")
+	ErrInvalidOperationCouldAgreeWithoutPrivateKey = errors.New("key: invalid operation : could not compute a shared secret without a private key")
+	ErrInvalidOperationNotSupported                = errors.New("key: invalid operation : not supported by this key")
+	ErrInvalidOperationThumbprintWithoutSecretKey  = errors.New("key: invalid operation : could not compute thumbprint without a secret key")
+	ErrIncompatiblePeerKey                         = errors.New("key: incompatible peer key")
+	ErrHashNotAvailable                            = errors.New("key: hash function not available")
+	ErrInvalidKeyID                                = errors.New("key: invalid key identifier")
+	ErrInvalidOperationExportWithoutPrivateKey     = errors.New("key: invalid operation : could not export without a private key")
+	ErrInvalidOperationDecryptWithoutPrivateKey    = errors.New("key: invalid operation : could not decrypt without a private key")
+	ErrInvalidDigestSize                           = errors.New("key: invalid digest size")
+	ErrInvalidContext                              = errors.New("key: signature context is too long")
+	ErrInvalidSeed                                 = errors.New("key: master seed is too short")
+	ErrInvalidDerivationPath                       = errors.New("key: invalid derivation path")
+	ErrDerivationFailed                            = errors.New("key: unable to derive a valid private key")
+	ErrMemoryLockNotSupported                      = errors.New("key: memory locking is not supported on this platform")
+	ErrInvalidPEM                                  = errors.New("key: invalid or unsupported PEM block")
+	ErrEncryptedPEM                                = errors.New("key: encrypted PEM block, a password is required")
+	ErrUnsupportedPEMEncryption                    = errors.New("key: unsupported PEM encryption scheme")
+	ErrIncorrectPassword                           = errors.New("key: incorrect password")
+	ErrKeyAlgorithmMismatch                        = errors.New("key: key material does not match the algorithm")
 	ErrAlgorithmNotSupported                       = errors.New("key: Algorithm not supported")
 )
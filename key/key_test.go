@@ -0,0 +1,31 @@
+package key
+
+import (
+	. "github.com/onsi/gomega"
+)
+
+// testKeys lists a generator for each supported key type, indexed by the
+// algorithm accepted by the importers
+var testKeys = map[string]func() (Key, error){
+	"Ed25519": Ed25519,
+	"Ed448":   Ed448,
+	"ES256":   ES256,
+	"ES384":   ES384,
+	"ES512":   ES512,
+	"RS256":   RS256,
+	"PS512":   PS512,
+	"ES256K":  ES256K,
+	"HS256":   HS256,
+	"X25519":  X25519,
+	"P-384":   ECDHP384,
+}
+
+// forEachKey generates a key of each supported type and runs the assertions
+// on it
+func forEachKey(fn func(alg string, k Key)) {
+	for alg, generate := range testKeys {
+		k, err := generate()
+		Expect(err).To(BeNil(), alg)
+		fn(alg, k)
+	}
+}
@@ -20,21 +20,56 @@ func FromString(input []byte) (Key, error) {
 // FromMap builds a Key instance from a map object
 func FromMap(input map[string]interface{}) (Key, error) {
 	var result rawJWK
-	err := mapstructure.Decode(input, &result)
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		TagName: "json",
+		Result:  &result,
+	})
 	if err != nil {
 		return nil, err
 	}
+	if err := decoder.Decode(input); err != nil {
+		return nil, err
+	}
 
 	return fromRaw(&result)
 }
 
 // fromRaw returns a concrete instance from the rawJWK specification
 func fromRaw(raw *rawJWK) (Key, error) {
-	switch raw.Algorithm {
+	k, err := decodeRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if a, ok := k.(attributed); ok {
+		a.load(raw)
+	}
+
+	return k, nil
+}
+
+func decodeRaw(raw *rawJWK) (Key, error) {
+	switch raw.KeyType {
 	case "EC":
+		if raw.Curve == "secp256k1" {
+			return toSecp256k1(raw)
+		}
+		if isECDHAlgorithm(raw.Algorithm) || (len(raw.Algorithm) == 0 && raw.PublicKeyUse == "enc") {
+			return toECDH(raw)
+		}
+		return toECDSA(raw)
+	case "RSA":
+		return toRSA(raw)
+	case "oct":
+		return toHMAC(raw)
+	case "OKP":
 		switch raw.Curve {
 		case "Ed25519":
 			return toEd25519(raw)
+		case "Ed448":
+			return toEd448(raw)
+		case "X25519":
+			return toECDH(raw)
 		}
 	default:
 	}
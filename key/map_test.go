@@ -0,0 +1,43 @@
+package key
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestFromMap_RoundTrip(t *testing.T) {
+	RegisterTestingT(t)
+
+	forEachKey(func(alg string, k Key) {
+		k.Metadata().KeyOperations = []string{OperationSign, OperationVerify}
+
+		out, err := json.Marshal(k)
+		Expect(err).To(BeNil(), alg)
+
+		var m map[string]interface{}
+		Expect(json.Unmarshal(out, &m)).To(BeNil(), alg)
+
+		loaded, err := FromMap(m)
+		Expect(err).To(BeNil(), alg)
+		Expect(loaded.ID()).To(Equal(k.ID()), alg)
+		Expect(loaded.Algorithm()).To(Equal(k.Algorithm()), alg)
+		Expect(loaded.HasPrivate()).To(BeTrue(), alg)
+		Expect(loaded.CreatedAt()).To(Equal(k.CreatedAt()), alg)
+
+		back, err := json.Marshal(loaded)
+		Expect(err).To(BeNil(), alg)
+		Expect(back).To(MatchJSON(out), alg)
+
+		// Numbers decoded as json.Number, as returned by the Vault client
+		decoder := json.NewDecoder(bytes.NewReader(out))
+		decoder.UseNumber()
+		Expect(decoder.Decode(&m)).To(BeNil(), alg)
+
+		loaded, err = FromMap(m)
+		Expect(err).To(BeNil(), alg)
+		Expect(loaded.CreatedAt()).To(Equal(k.CreatedAt()), alg)
+	})
+}
@@ -0,0 +1,15 @@
+package key
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// excludeFromDump removes the pages of the buffer from core dumps
+func excludeFromDump(b []byte) {
+	_ = unix.Madvise(b, unix.MADV_DONTDUMP)
+}
+
+// includeInDump restores the default behaviour, as the pages will be reused
+func includeInDump(b []byte) {
+	_ = unix.Madvise(b, unix.MADV_DODUMP)
+}
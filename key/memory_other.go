@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package key
+
+func allocLocked(size int) ([]byte, error) {
+	return nil, ErrMemoryLockNotSupported
+}
+
+func freeLocked(b []byte) {
+	wipe(b)
+}
@@ -0,0 +1,37 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package key
+
+import (
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// allocLocked allocates a buffer starting on a memory page which is not
+// shared with any other object, and locks its pages in RAM. The buffer stays
+// on the Go heap as the standard library caches keys by address.
+func allocLocked(size int) ([]byte, error) {
+	pageSize := os.Getpagesize()
+	pages := (size + pageSize - 1) / pageSize * pageSize
+
+	// One extra page to align the start of the buffer
+	buf := make([]byte, pages+pageSize)
+	offset := (pageSize - int(uintptr(unsafe.Pointer(&buf[0]))%uintptr(pageSize))) % pageSize
+	b := buf[offset : offset+size : offset+size]
+
+	if err := unix.Mlock(b); err != nil {
+		return nil, err
+	}
+	excludeFromDump(b)
+
+	return b, nil
+}
+
+// freeLocked wipes and unlocks a buffer returned by allocLocked
+func freeLocked(b []byte) {
+	wipe(b)
+	includeInDump(b)
+	_ = unix.Munlock(b)
+}
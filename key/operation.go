@@ -0,0 +1,80 @@
+package key
+
+import "fmt"
+
+// Key operations: RFC 7517 Section 4.3
+const (
+	OperationSign       = "sign"
+	OperationVerify     = "verify"
+	OperationEncrypt    = "encrypt"
+	OperationDecrypt    = "decrypt"
+	OperationWrapKey    = "wrapKey"
+	OperationUnwrapKey  = "unwrapKey"
+	OperationDeriveKey  = "deriveKey"
+	OperationDeriveBits = "deriveBits"
+)
+
+// OperationNotPermittedError is raised when the key use or key operations
+// declared by the key forbid the requested operation
+type OperationNotPermittedError struct {
+	KeyID     string
+	Operation string
+}
+
+func (e *OperationNotPermittedError) Error() string {
+	return fmt.Sprintf("key: operation %q is not permitted by key %q", e.Operation, e.KeyID)
+}
+
+// permitter is implemented by keys restricting their operations
+type permitter interface {
+	permits(operation string) bool
+}
+
+// Permits returns true if the key use and key operations allow the given
+// operation
+func Permits(k Key, operation string) bool {
+	if p, ok := k.(permitter); ok {
+		return p.permits(operation)
+	}
+	return true
+}
+
+// CanSign returns true if the key holds private material and is allowed to sign
+func CanSign(k Key) bool {
+	return k.HasPrivate() && Permits(k, OperationSign)
+}
+
+// -----------------------------------------------------------------------------
+
+// permits applies the "use" (JWK 4.2) and "key_ops" (JWK 4.3) restrictions
+func (a *attributes) permits(operation string) bool {
+	signature := operation == OperationSign || operation == OperationVerify
+
+	switch a.metadata.Use {
+	case "sig":
+		if !signature {
+			return false
+		}
+	case "enc":
+		if signature {
+			return false
+		}
+	}
+
+	if len(a.metadata.KeyOperations) == 0 {
+		return true
+	}
+	for _, op := range a.metadata.KeyOperations {
+		if op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func operationNotPermitted(k Key, operation string) error {
+	return &OperationNotPermittedError{
+		KeyID:     k.ID(),
+		Operation: operation,
+	}
+}
@@ -0,0 +1,51 @@
+package key
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestOperation_Enforcement(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, _ := ES256()
+	sig, err := key.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+
+	// Verify only
+	key.Metadata().KeyOperations = []string{OperationVerify}
+	Expect(CanSign(key)).To(BeFalse())
+
+	_, err = key.Sign([]byte("payload"))
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+	Expect(err.(*OperationNotPermittedError).Operation).To(Equal(OperationSign))
+	Expect(err.(*OperationNotPermittedError).KeyID).To(Equal(key.ID()))
+
+	valid, err := key.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	// Encryption key
+	key.Metadata().KeyOperations = nil
+	key.Metadata().Use = "enc"
+	Expect(CanSign(key)).To(BeFalse())
+
+	_, err = key.Sign([]byte("payload"))
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+	_, err = key.Public().Verify([]byte("payload"), sig)
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+
+	// Signature key
+	key.Metadata().Use = "sig"
+	Expect(CanSign(key)).To(BeTrue())
+	Expect(CanSign(key.Public())).To(BeFalse())
+	Expect(Permits(key, OperationEncrypt)).To(BeFalse())
+
+	// Key agreement keys never sign
+	agreement, _ := X25519()
+	Expect(CanSign(agreement)).To(BeFalse())
+	agreement.Metadata().KeyOperations = []string{OperationDeriveKey}
+	_, err = agreement.(KeyAgreement).SharedSecret(agreement.Public())
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+}
@@ -0,0 +1,133 @@
+package key
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+)
+
+// PEM block types
+const (
+	pemPrivateKey          = "PRIVATE KEY"
+	pemEncryptedPrivateKey = "ENCRYPTED PRIVATE KEY"
+	pemPublicKey           = "PUBLIC KEY"
+	pemECPrivateKey        = "EC PRIVATE KEY"
+	pemRSAPrivateKey       = "RSA PRIVATE KEY"
+)
+
+// FromPEM builds a Key instance of the given algorithm from the first PEM block
+// of the input. PKCS#8 private keys, PKIX public keys, and legacy SEC1 (EC
+// PRIVATE KEY) and PKCS#1 (RSA PRIVATE KEY) blocks are supported. PEM does not
+// tell a signature key from a key agreement one, nor RSA padding, so alg is
+// required: a signature algorithm (Ed25519, Ed448, ES256, ES384, ES512,
+// ES256K, ES256K-R, RS256 ... PS512) or the curve of a key agreement key
+// (X25519, P-256, P-384, P-521). ErrKeyAlgorithmMismatch is raised when the
+// key does not match alg.
+func FromPEM(input []byte, alg string) (Key, error) {
+	block, _ := pem.Decode(input)
+	if block == nil {
+		return nil, ErrInvalidPEM
+	}
+
+	switch block.Type {
+	case pemPrivateKey:
+		priv, err := parsePKCS8(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return fromCryptoPrivateKey(priv, alg)
+	case pemPublicKey:
+		pub, err := parsePKIX(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return fromCryptoPublicKey(pub, alg)
+	case pemECPrivateKey:
+		priv, err := parseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return fromCryptoPrivateKey(priv, alg)
+	case pemRSAPrivateKey:
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return fromCryptoPrivateKey(priv, alg)
+	case pemEncryptedPrivateKey:
+		return nil, ErrEncryptedPEM
+	}
+
+	return nil, ErrInvalidPEM
+}
+
+// FromEncryptedPEM builds a Key instance of the given algorithm (see FromPEM)
+// from an encrypted PKCS#8 PEM block (ENCRYPTED PRIVATE KEY) using the given
+// password
+func FromEncryptedPEM(input, password []byte, alg string) (Key, error) {
+	block, _ := pem.Decode(input)
+	if block == nil || block.Type != pemEncryptedPrivateKey {
+		return nil, ErrInvalidPEM
+	}
+
+	der, err := decryptPKCS8(block.Bytes, password)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(der)
+
+	priv, err := parsePKCS8(der)
+	if err != nil {
+		// Valid padding obtained by chance with a wrong password
+		return nil, ErrIncorrectPassword
+	}
+
+	return fromCryptoPrivateKey(priv, alg)
+}
+
+// MarshalPEM encodes the key as a PKCS#8 PEM block when it holds a private
+// key, or as a PKIX PEM block for a public key
+func MarshalPEM(k Key) ([]byte, error) {
+	ck, ok := k.(cryptoKey)
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	if k.HasPrivate() {
+		der, err := marshalPKCS8(ck.cryptoPrivateKey())
+		if err != nil {
+			return nil, err
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+	}
+
+	der, err := marshalPKIX(ck.cryptoPublicKey())
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+// MarshalEncryptedPEM encodes the private key as an encrypted PKCS#8 PEM block
+// (PBES2, PBKDF2-HMAC-SHA256, AES-256-CBC) using the given password
+func MarshalEncryptedPEM(k Key, password []byte) ([]byte, error) {
+	ck, ok := k.(cryptoKey)
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationExportWithoutPrivateKey
+	}
+
+	der, err := marshalPKCS8(ck.cryptoPrivateKey())
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(der)
+
+	encrypted, err := encryptPKCS8(der, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: pemEncryptedPrivateKey, Bytes: encrypted}), nil
+}
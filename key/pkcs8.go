@@ -0,0 +1,393 @@
+package key
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"hash"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/pbkdf2"
+
+	// Register hash functions
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+var (
+	oidPublicKeyEd448     = asn1.ObjectIdentifier{1, 3, 101, 113}
+	oidPublicKeyECDSA     = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveSecp256k = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+
+	oidPBES2          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+	oidAES128CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC      = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+)
+
+const (
+	// PBKDF2 iteration count used for encrypted private keys
+	pbkdf2Iterations = 600000
+	pbkdf2SaltSize   = 16
+	// Maximal PBKDF2 iteration count accepted when decrypting, bounds the
+	// work a crafted key could require to about a second
+	pbkdf2MaxIterations = 1000000
+)
+
+// pkcs8 reflects a PKCS#8 PrivateKeyInfo: RFC 5208 / RFC 5958
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	Attributes asn1.RawValue `asn1:"optional,tag:0"`
+	PublicKey  asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// ecPrivateKey reflects a SEC1 ECPrivateKey: RFC 5915
+type ecPrivateKey struct {
+	Version       int
+	PrivateKey    []byte
+	NamedCurveOID asn1.ObjectIdentifier `asn1:"optional,explicit,tag:0"`
+	PublicKey     asn1.BitString        `asn1:"optional,explicit,tag:1"`
+}
+
+// subjectPublicKeyInfo reflects a PKIX SubjectPublicKeyInfo: RFC 5280
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// encryptedPrivateKeyInfo reflects a PKCS#8 EncryptedPrivateKeyInfo: RFC 5208
+type encryptedPrivateKeyInfo struct {
+	Algo          pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+// pbes2Params reflects PBES2-params: RFC 8018 Appendix A.4
+type pbes2Params struct {
+	KeyDerivationFunc pkix.AlgorithmIdentifier
+	EncryptionScheme  pkix.AlgorithmIdentifier
+}
+
+// pbkdf2Params reflects PBKDF2-params: RFC 8018 Appendix A.2
+type pbkdf2Params struct {
+	Salt           []byte
+	IterationCount int
+	KeyLength      int                      `asn1:"optional"`
+	PRF            pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// -----------------------------------------------------------------------------
+
+// marshalPKCS8 encodes the native private key as PKCS#8 DER, completing the
+// standard library with Ed448 and secp256k1 support
+func marshalPKCS8(priv crypto.PrivateKey) ([]byte, error) {
+	switch pk := priv.(type) {
+	case ed448.PrivateKey:
+		// RFC 8410 Section 7: CurvePrivateKey ::= OCTET STRING
+		seed, err := asn1.Marshal(pk.Seed())
+		if err != nil {
+			return nil, err
+		}
+		return asn1.Marshal(pkcs8{
+			Algo:       pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyEd448},
+			PrivateKey: seed,
+		})
+	case *secp256k1.PrivateKey:
+		sec1, err := marshalSecp256k1ECPrivateKey(pk)
+		if err != nil {
+			return nil, err
+		}
+		params, err := asn1.Marshal(oidNamedCurveSecp256k)
+		if err != nil {
+			return nil, err
+		}
+		return asn1.Marshal(pkcs8{
+			Algo: pkix.AlgorithmIdentifier{
+				Algorithm:  oidPublicKeyECDSA,
+				Parameters: asn1.RawValue{FullBytes: params},
+			},
+			PrivateKey: sec1,
+		})
+	}
+
+	return x509.MarshalPKCS8PrivateKey(priv)
+}
+
+// parsePKCS8 decodes a PKCS#8 DER private key, completing the standard library
+// with Ed448 and secp256k1 support
+func parsePKCS8(der []byte) (crypto.PrivateKey, error) {
+	var info pkcs8
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case info.Algo.Algorithm.Equal(oidPublicKeyEd448):
+		var seed []byte
+		if _, err := asn1.Unmarshal(info.PrivateKey, &seed); err != nil {
+			return nil, err
+		}
+		if len(seed) != ed448.SeedSize {
+			return nil, errors.New("key: invalid ed448 private key size")
+		}
+		return ed448.NewKeyFromSeed(seed), nil
+	case info.Algo.Algorithm.Equal(oidPublicKeyECDSA):
+		var namedCurve asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &namedCurve); err == nil && namedCurve.Equal(oidNamedCurveSecp256k) {
+			return parseSecp256k1ECPrivateKey(info.PrivateKey)
+		}
+	}
+
+	return x509.ParsePKCS8PrivateKey(der)
+}
+
+// marshalPKIX encodes the native public key as PKIX DER, completing the
+// standard library with Ed448 and secp256k1 support
+func marshalPKIX(pub crypto.PublicKey) ([]byte, error) {
+	switch pk := pub.(type) {
+	case ed448.PublicKey:
+		return asn1.Marshal(subjectPublicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidPublicKeyEd448},
+			PublicKey: asn1.BitString{Bytes: pk, BitLength: 8 * len(pk)},
+		})
+	case *secp256k1.PublicKey:
+		params, err := asn1.Marshal(oidNamedCurveSecp256k)
+		if err != nil {
+			return nil, err
+		}
+		point := pk.SerializeUncompressed()
+		return asn1.Marshal(subjectPublicKeyInfo{
+			Algorithm: pkix.AlgorithmIdentifier{
+				Algorithm:  oidPublicKeyECDSA,
+				Parameters: asn1.RawValue{FullBytes: params},
+			},
+			PublicKey: asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+		})
+	}
+
+	return x509.MarshalPKIXPublicKey(pub)
+}
+
+// parsePKIX decodes a PKIX DER public key, completing the standard library
+// with Ed448 and secp256k1 support
+func parsePKIX(der []byte) (crypto.PublicKey, error) {
+	var info subjectPublicKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case info.Algorithm.Algorithm.Equal(oidPublicKeyEd448):
+		if len(info.PublicKey.Bytes) != ed448.PublicKeySize {
+			return nil, errors.New("key: invalid ed448 public key size")
+		}
+		return ed448.PublicKey(info.PublicKey.Bytes), nil
+	case info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA):
+		var namedCurve asn1.ObjectIdentifier
+		if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &namedCurve); err == nil && namedCurve.Equal(oidNamedCurveSecp256k) {
+			return secp256k1.ParsePubKey(info.PublicKey.Bytes)
+		}
+	}
+
+	return x509.ParsePKIXPublicKey(der)
+}
+
+// parseECPrivateKey decodes a SEC1 DER private key ("EC PRIVATE KEY")
+func parseECPrivateKey(der []byte) (crypto.PrivateKey, error) {
+	var sec1 ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &sec1); err != nil {
+		return nil, err
+	}
+	if sec1.NamedCurveOID.Equal(oidNamedCurveSecp256k) {
+		return parseSecp256k1ECPrivateKey(der)
+	}
+
+	return x509.ParseECPrivateKey(der)
+}
+
+func marshalSecp256k1ECPrivateKey(pk *secp256k1.PrivateKey) ([]byte, error) {
+	point := pk.PubKey().SerializeUncompressed()
+	return asn1.Marshal(ecPrivateKey{
+		Version:    1,
+		PrivateKey: pk.Serialize(),
+		PublicKey:  asn1.BitString{Bytes: point, BitLength: 8 * len(point)},
+	})
+}
+
+func parseSecp256k1ECPrivateKey(der []byte) (crypto.PrivateKey, error) {
+	var sec1 ecPrivateKey
+	if _, err := asn1.Unmarshal(der, &sec1); err != nil {
+		return nil, err
+	}
+	if sec1.Version != 1 || len(sec1.PrivateKey) != secp256k1CoordinateSize {
+		return nil, errors.New("key: invalid secp256k1 private key")
+	}
+	return secp256k1.PrivKeyFromBytes(sec1.PrivateKey), nil
+}
+
+// -----------------------------------------------------------------------------
+
+// encryptPKCS8 encrypts a PKCS#8 DER private key using PBES2 with
+// PBKDF2-HMAC-SHA256 and AES-256-CBC: RFC 8018
+func encryptPKCS8(der, password []byte) ([]byte, error) {
+	salt := make([]byte, pbkdf2SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+
+	kdfParams, err := asn1.Marshal(pbkdf2Params{
+		Salt:           salt,
+		IterationCount: pbkdf2Iterations,
+		KeyLength:      32,
+		PRF: pkix.AlgorithmIdentifier{
+			Algorithm:  oidHMACWithSHA256,
+			Parameters: asn1.NullRawValue,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	ivParams, err := asn1.Marshal(iv)
+	if err != nil {
+		return nil, err
+	}
+	schemeParams, err := asn1.Marshal(pbes2Params{
+		KeyDerivationFunc: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBKDF2,
+			Parameters: asn1.RawValue{FullBytes: kdfParams},
+		},
+		EncryptionScheme: pkix.AlgorithmIdentifier{
+			Algorithm:  oidAES256CBC,
+			Parameters: asn1.RawValue{FullBytes: ivParams},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dk := pbkdf2.Key(password, salt, pbkdf2Iterations, 32, crypto.SHA256.New)
+	block, err := aes.NewCipher(dk)
+	wipe(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	// PKCS#7 padding
+	padding := aes.BlockSize - len(der)%aes.BlockSize
+	plaintext := append(append([]byte{}, der...), bytes.Repeat([]byte{byte(padding)}, padding)...)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+	wipe(plaintext)
+
+	return asn1.Marshal(encryptedPrivateKeyInfo{
+		Algo: pkix.AlgorithmIdentifier{
+			Algorithm:  oidPBES2,
+			Parameters: asn1.RawValue{FullBytes: schemeParams},
+		},
+		EncryptedData: ciphertext,
+	})
+}
+
+// decryptPKCS8 decrypts a PBES2 encrypted PKCS#8 private key: RFC 8018. The
+// caller must wipe the returned plaintext once parsed.
+func decryptPKCS8(der, password []byte) ([]byte, error) {
+	var info encryptedPrivateKeyInfo
+	if _, err := asn1.Unmarshal(der, &info); err != nil {
+		return nil, err
+	}
+	if !info.Algo.Algorithm.Equal(oidPBES2) {
+		return nil, ErrUnsupportedPEMEncryption
+	}
+
+	var scheme pbes2Params
+	if _, err := asn1.Unmarshal(info.Algo.Parameters.FullBytes, &scheme); err != nil {
+		return nil, err
+	}
+	if !scheme.KeyDerivationFunc.Algorithm.Equal(oidPBKDF2) {
+		return nil, ErrUnsupportedPEMEncryption
+	}
+
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(scheme.KeyDerivationFunc.Parameters.FullBytes, &kdf); err != nil {
+		return nil, err
+	}
+	if kdf.IterationCount < 1 || kdf.IterationCount > pbkdf2MaxIterations {
+		return nil, ErrUnsupportedPEMEncryption
+	}
+
+	var prf func() hash.Hash
+	switch {
+	case len(kdf.PRF.Algorithm) == 0, kdf.PRF.Algorithm.Equal(oidHMACWithSHA1):
+		prf = crypto.SHA1.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA256):
+		prf = crypto.SHA256.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA384):
+		prf = crypto.SHA384.New
+	case kdf.PRF.Algorithm.Equal(oidHMACWithSHA512):
+		prf = crypto.SHA512.New
+	default:
+		return nil, ErrUnsupportedPEMEncryption
+	}
+
+	var keySize int
+	switch {
+	case scheme.EncryptionScheme.Algorithm.Equal(oidAES128CBC):
+		keySize = 16
+	case scheme.EncryptionScheme.Algorithm.Equal(oidAES192CBC):
+		keySize = 24
+	case scheme.EncryptionScheme.Algorithm.Equal(oidAES256CBC):
+		keySize = 32
+	default:
+		return nil, ErrUnsupportedPEMEncryption
+	}
+	if kdf.KeyLength != 0 && kdf.KeyLength != keySize {
+		return nil, ErrUnsupportedPEMEncryption
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(scheme.EncryptionScheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, err
+	}
+	if len(iv) != aes.BlockSize || len(info.EncryptedData) == 0 || len(info.EncryptedData)%aes.BlockSize != 0 {
+		return nil, ErrIncorrectPassword
+	}
+
+	dk := pbkdf2.Key(password, kdf.Salt, kdf.IterationCount, keySize, prf)
+	block, err := aes.NewCipher(dk)
+	wipe(dk)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(info.EncryptedData))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, info.EncryptedData)
+
+	// Remove PKCS#7 padding, a wrong password usually produces an invalid one
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize ||
+		subtle.ConstantTimeCompare(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) != 1 {
+		wipe(plaintext)
+		return nil, ErrIncorrectPassword
+	}
+
+	return plaintext[:len(plaintext)-padding], nil
+}
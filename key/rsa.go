@@ -0,0 +1,336 @@
+package key
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	// Register hash functions
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+const (
+	// DefaultRSAKeySize is the modulus size used by RSA shortcut generators
+	DefaultRSAKeySize = 2048
+	// MinimumRSAKeySize is the smallest modulus size accepted for generation (JWA 3.3)
+	MinimumRSAKeySize = 2048
+)
+
+// rsaParams describes the hash and the padding scheme used by an RSA JWS algorithm
+type rsaParams struct {
+	alg  string
+	hash crypto.Hash
+	pss  bool
+}
+
+var rsaAlgorithms = map[string]*rsaParams{
+	"RS256": {alg: "RS256", hash: crypto.SHA256},
+	"RS384": {alg: "RS384", hash: crypto.SHA384},
+	"RS512": {alg: "RS512", hash: crypto.SHA512},
+	"PS256": {alg: "PS256", hash: crypto.SHA256, pss: true},
+	"PS384": {alg: "PS384", hash: crypto.SHA384, pss: true},
+	"PS512": {alg: "PS512", hash: crypto.SHA512, pss: true},
+}
+
+type rsaKey struct {
+	attributes
+
+	params *rsaParams
+	priv   *rsa.PrivateKey
+	pub    *rsa.PublicKey
+}
+
+// RSA key holder using the given JWS algorithm and modulus size
+func RSA(alg string, bits int) (Key, error) {
+	params, ok := rsaAlgorithms[alg]
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+	if bits < MinimumRSAKeySize {
+		return nil, errors.New("key: rsa modulus size is too small")
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rsaKey{
+		attributes: newAttributes(),
+		params:     params,
+		priv:       privateKey,
+		pub:        &privateKey.PublicKey,
+	}, nil
+}
+
+// RS256 key holder (RSASSA-PKCS1-v1_5 using SHA-256)
+func RS256() (Key, error) {
+	return RSA("RS256", DefaultRSAKeySize)
+}
+
+// RS384 key holder (RSASSA-PKCS1-v1_5 using SHA-384)
+func RS384() (Key, error) {
+	return RSA("RS384", DefaultRSAKeySize)
+}
+
+// RS512 key holder (RSASSA-PKCS1-v1_5 using SHA-512)
+func RS512() (Key, error) {
+	return RSA("RS512", DefaultRSAKeySize)
+}
+
+// PS256 key holder (RSASSA-PSS using SHA-256 and MGF1 with SHA-256)
+func PS256() (Key, error) {
+	return RSA("PS256", DefaultRSAKeySize)
+}
+
+// PS384 key holder (RSASSA-PSS using SHA-384 and MGF1 with SHA-384)
+func PS384() (Key, error) {
+	return RSA("PS384", DefaultRSAKeySize)
+}
+
+// PS512 key holder (RSASSA-PSS using SHA-512 and MGF1 with SHA-512)
+func PS512() (Key, error) {
+	return RSA("PS512", DefaultRSAKeySize)
+}
+
+func toRSA(raw *rawJWK) (Key, error) {
+	alg := raw.Algorithm
+	if len(alg) == 0 {
+		alg = "RS256"
+	}
+	params, ok := rsaAlgorithms[alg]
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	n, err := decodeBigInt(raw.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(raw.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("key: invalid rsa public exponent")
+	}
+
+	k := &rsaKey{
+		params: params,
+		pub: &rsa.PublicKey{
+			N: n,
+			E: int(e.Int64()),
+		},
+	}
+	if len(raw.D) > 0 {
+		if len(raw.P) == 0 || len(raw.Q) == 0 {
+			return nil, errors.New("key: rsa private key without prime factors is not supported")
+		}
+
+		priv := &rsa.PrivateKey{
+			PublicKey: *k.pub,
+		}
+		if priv.D, err = decodeBigInt(raw.D); err != nil {
+			return nil, err
+		}
+		p, err := decodeBigInt(raw.P)
+		if err != nil {
+			return nil, err
+		}
+		q, err := decodeBigInt(raw.Q)
+		if err != nil {
+			return nil, err
+		}
+		priv.Primes = []*big.Int{p, q}
+
+		if err := priv.Validate(); err != nil {
+			return nil, err
+		}
+		priv.Precompute()
+
+		// CRT values are recomputed from primes, but must match when supplied
+		crt := []struct {
+			value    string
+			expected *big.Int
+		}{
+			{raw.Dp, priv.Precomputed.Dp},
+			{raw.Dq, priv.Precomputed.Dq},
+			{raw.Qi, priv.Precomputed.Qinv},
+		}
+		for _, c := range crt {
+			if len(c.value) == 0 {
+				continue
+			}
+			v, err := decodeBigInt(c.value)
+			if err != nil {
+				return nil, err
+			}
+			if v.Cmp(c.expected) != 0 {
+				return nil, errors.New("key: invalid rsa CRT parameters")
+			}
+		}
+
+		k.priv = priv
+	}
+
+	return k, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *rsaKey) Algorithm() string {
+	return k.params.alg
+}
+
+func (k *rsaKey) ID() string {
+	if len(k.kid) > 0 {
+		return k.kid
+	}
+	return k.libtrustID()
+}
+
+func (k *rsaKey) libtrustID() string {
+	return keyIDFromCryptoKey(k.pub)
+}
+
+func (k *rsaKey) HasPrivate() bool {
+	return k.priv != nil
+}
+
+func (k *rsaKey) HasPublic() bool {
+	return k.pub != nil
+}
+
+func (k *rsaKey) Public() Key {
+	return &rsaKey{
+		attributes: k.clone(),
+		params:     k.params,
+		pub:        k.pub,
+	}
+}
+
+func (k *rsaKey) cryptoPrivateKey() crypto.PrivateKey {
+	if k.priv == nil {
+		return nil
+	}
+	return k.priv
+}
+
+func (k *rsaKey) cryptoPublicKey() crypto.PublicKey {
+	return k.pub
+}
+
+func (k *rsaKey) Sign(data []byte) ([]byte, error) {
+	return k.SignWithOptions(data, nil)
+}
+
+func (k *rsaKey) Verify(data, sig []byte) (bool, error) {
+	return k.VerifyWithOptions(data, sig, nil)
+}
+
+// SignWithOptions signs the message, or its digest when pre-hashed with the
+// algorithm hash function
+func (k *rsaKey) SignWithOptions(data []byte, opts *SignOptions) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+
+	digest, err := digestWithOptions(k.params.hash, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.params.pss {
+		return rsa.SignPSS(rand.Reader, k.priv, k.params.hash, digest, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		})
+	}
+	return rsa.SignPKCS1v15(rand.Reader, k.priv, k.params.hash, digest)
+}
+
+func (k *rsaKey) VerifyWithOptions(data, sig []byte, opts *SignOptions) (bool, error) {
+	if !k.HasPublic() {
+		return false, ErrInvalidOperationCouldVerifyWithoutPublicKey
+	}
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+
+	digest, err := digestWithOptions(k.params.hash, data, opts)
+	if err != nil {
+		return false, err
+	}
+
+	if k.params.pss {
+		err = rsa.VerifyPSS(k.pub, k.params.hash, digest, sig, &rsa.PSSOptions{
+			SaltLength: rsa.PSSSaltLengthEqualsHash,
+		})
+	} else {
+		err = rsa.VerifyPKCS1v15(k.pub, k.params.hash, digest, sig)
+	}
+
+	return err == nil, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *rsaKey) MarshalJSON() ([]byte, error) {
+	r := k.raw()
+	r.KeyID = k.ID()
+	k.fill(r)
+
+	return json.Marshal(r)
+}
+
+func (k *rsaKey) Thumbprint(h crypto.Hash) ([]byte, error) {
+	return thumbprint(k.raw(), h)
+}
+
+func (k *rsaKey) raw() *rawJWK {
+	r := &rawJWK{
+		KeyType:   "RSA",
+		Algorithm: k.Algorithm(),
+		N:         encodeBigInt(k.pub.N),
+		E:         encodeBigInt(big.NewInt(int64(k.pub.E))),
+	}
+	if k.HasPrivate() {
+		r.D = encodeBigInt(k.priv.D)
+		if len(k.priv.Primes) == 2 {
+			r.P = encodeBigInt(k.priv.Primes[0])
+			r.Q = encodeBigInt(k.priv.Primes[1])
+			r.Dp = encodeBigInt(k.priv.Precomputed.Dp)
+			r.Dq = encodeBigInt(k.priv.Precomputed.Dq)
+			r.Qi = encodeBigInt(k.priv.Precomputed.Qinv)
+		}
+	}
+
+	return r
+}
+
+// -----------------------------------------------------------------------------
+
+func encodeBigInt(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(v.Bytes())
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	if len(value) == 0 {
+		return nil, errors.New("key: missing rsa parameter")
+	}
+	b, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
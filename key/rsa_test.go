@@ -0,0 +1,81 @@
+package key
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestRSA_Generation(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, alg := range []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"} {
+		key, err := RSA(alg, 2048)
+		Expect(err).To(BeNil())
+		Expect(key.Algorithm()).To(Equal(alg))
+		Expect(key.HasPrivate()).To(BeTrue())
+		Expect(key.HasPublic()).To(BeTrue())
+
+		pub := key.Public()
+		Expect(pub.Algorithm()).To(Equal(alg))
+		Expect(pub.HasPrivate()).To(BeFalse())
+		Expect(pub.HasPublic()).To(BeTrue())
+
+		sig, err := key.Sign([]byte("payload"))
+		Expect(err).To(BeNil())
+
+		valid, err := pub.Verify([]byte("payload"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeTrue())
+
+		valid, err = pub.Verify([]byte("tampered"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeFalse())
+	}
+
+	_, err := RSA("RS256", 1024)
+	Expect(err).ToNot(BeNil())
+
+	_, err = RSA("HS256", 2048)
+	Expect(err).To(Equal(ErrAlgorithmNotSupported))
+}
+
+func TestRSA_Serialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, err := PS256()
+	Expect(err).To(BeNil())
+
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+
+	var fields map[string]interface{}
+	Expect(json.Unmarshal(out, &fields)).To(BeNil())
+	for _, name := range []string{"kty", "alg", "n", "e", "d", "p", "q", "dp", "dq", "qi"} {
+		Expect(fields).To(HaveKey(name))
+	}
+	Expect(fields["kty"]).To(Equal("RSA"))
+	Expect(fields["e"]).To(Equal("AQAB"))
+
+	back, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(back.Algorithm()).To(Equal("PS256"))
+	Expect(back.ID()).To(Equal(key.ID()))
+	Expect(back.HasPrivate()).To(BeTrue())
+
+	sig, err := back.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+	valid, err := key.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	out, err = json.Marshal(key.Public())
+	Expect(err).To(BeNil())
+	Expect(string(out)).ToNot(ContainSubstring(`"d"`))
+
+	pub, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(pub.HasPrivate()).To(BeFalse())
+	Expect(pub.ID()).To(Equal(key.ID()))
+}
@@ -0,0 +1,276 @@
+package key
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+const (
+	secp256k1CoordinateSize = 32
+	// Compact signature recovery code offset inherited from Bitcoin
+	secp256k1CompactMagic = 27
+)
+
+type secp256k1Key struct {
+	attributes
+
+	recoverable bool
+	priv        *secp256k1.PrivateKey
+	pub         *secp256k1.PublicKey
+}
+
+// ES256K key holder (ECDSA using secp256k1 and SHA-256)
+func ES256K() (Key, error) {
+	return generateSecp256k1(false)
+}
+
+// ES256KRecoverable key holder (ECDSA using secp256k1 and SHA-256), producing
+// signatures that embed a recovery identifier (R || S || V) so that the public
+// key can be recovered from the signature using RecoverPublicKey.
+func ES256KRecoverable() (Key, error) {
+	return generateSecp256k1(true)
+}
+
+func generateSecp256k1(recoverable bool) (Key, error) {
+	privateKey, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &secp256k1Key{
+		attributes:  newAttributes(),
+		recoverable: recoverable,
+		priv:        privateKey,
+		pub:         privateKey.PubKey(),
+	}, nil
+}
+
+// RecoverPublicKey returns the secp256k1 public key used to produce the given
+// recoverable signature (ES256K-R) over data.
+func RecoverPublicKey(data, sig []byte) (Key, error) {
+	if len(sig) != 2*secp256k1CoordinateSize+1 {
+		return nil, ErrInvalidSignature
+	}
+
+	// Convert R || S || V to the compact <V><R><S> format
+	compact := make([]byte, 0, len(sig))
+	compact = append(compact, secp256k1CompactMagic+sig[2*secp256k1CoordinateSize])
+	compact = append(compact, sig[:2*secp256k1CoordinateSize]...)
+
+	hash := sha256.Sum256(data)
+	pub, _, err := secp256k1ecdsa.RecoverCompact(compact, hash[:])
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+
+	return &secp256k1Key{
+		recoverable: true,
+		pub:         pub,
+	}, nil
+}
+
+func toSecp256k1(raw *rawJWK) (Key, error) {
+	var recoverable bool
+	switch raw.Algorithm {
+	case "", "ES256K":
+	case "ES256K-R":
+		recoverable = true
+	default:
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	x, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.Y)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != secp256k1CoordinateSize || len(y) != secp256k1CoordinateSize {
+		return nil, errors.New("key: invalid secp256k1 coordinate size")
+	}
+
+	// Uncompressed point encoding
+	point := append([]byte{4}, x...)
+	point = append(point, y...)
+	pub, err := secp256k1.ParsePubKey(point)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &secp256k1Key{
+		recoverable: recoverable,
+		pub:         pub,
+	}
+	if len(raw.D) > 0 {
+		d, err := base64.URLEncoding.WithPadding(base64.NoPadding).DecodeString(raw.D)
+		if err != nil {
+			return nil, err
+		}
+		if len(d) != secp256k1CoordinateSize {
+			return nil, errors.New("key: invalid secp256k1 private key size")
+		}
+		k.priv = secp256k1.PrivKeyFromBytes(d)
+		if !k.priv.PubKey().IsEqual(pub) {
+			return nil, errors.New("key: secp256k1 private key does not match public key")
+		}
+	}
+
+	return k, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *secp256k1Key) Algorithm() string {
+	if k.recoverable {
+		return "ES256K-R"
+	}
+	return "ES256K"
+}
+
+func (k *secp256k1Key) ID() string {
+	if len(k.kid) > 0 {
+		return k.kid
+	}
+	return k.libtrustID()
+}
+
+func (k *secp256k1Key) libtrustID() string {
+	return keyIDFromData(k.pub.SerializeUncompressed())
+}
+
+func (k *secp256k1Key) HasPrivate() bool {
+	return k.priv != nil
+}
+
+func (k *secp256k1Key) HasPublic() bool {
+	return k.pub != nil
+}
+
+func (k *secp256k1Key) Public() Key {
+	return &secp256k1Key{
+		attributes:  k.clone(),
+		recoverable: k.recoverable,
+		pub:         k.pub,
+	}
+}
+
+func (k *secp256k1Key) cryptoPrivateKey() crypto.PrivateKey {
+	if k.priv == nil {
+		return nil
+	}
+	return k.priv
+}
+
+func (k *secp256k1Key) cryptoPublicKey() crypto.PublicKey {
+	return k.pub
+}
+
+func (k *secp256k1Key) Sign(data []byte) ([]byte, error) {
+	return k.SignWithOptions(data, nil)
+}
+
+func (k *secp256k1Key) Verify(data, sig []byte) (bool, error) {
+	return k.VerifyWithOptions(data, sig, nil)
+}
+
+// SignWithOptions signs the message, or its SHA-256 digest when pre-hashed
+func (k *secp256k1Key) SignWithOptions(data []byte, opts *SignOptions) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+
+	hash, err := digestWithOptions(crypto.SHA256, data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if k.recoverable {
+		// Convert the compact <V><R><S> format to R || S || V
+		compact := secp256k1ecdsa.SignCompact(k.priv, hash, false)
+		sig := make([]byte, 0, len(compact))
+		sig = append(sig, compact[1:]...)
+		return append(sig, compact[0]-secp256k1CompactMagic), nil
+	}
+
+	signature := secp256k1ecdsa.Sign(k.priv, hash)
+	r, s := signature.R(), signature.S()
+
+	sig := make([]byte, 2*secp256k1CoordinateSize)
+	r.PutBytesUnchecked(sig[:secp256k1CoordinateSize])
+	s.PutBytesUnchecked(sig[secp256k1CoordinateSize:])
+	return sig, nil
+}
+
+func (k *secp256k1Key) VerifyWithOptions(data, sig []byte, opts *SignOptions) (bool, error) {
+	if !k.HasPublic() {
+		return false, ErrInvalidOperationCouldVerifyWithoutPublicKey
+	}
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+
+	hash, err := digestWithOptions(crypto.SHA256, data, opts)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case len(sig) == 2*secp256k1CoordinateSize && !k.recoverable:
+	case len(sig) == 2*secp256k1CoordinateSize+1 && k.recoverable:
+		sig = sig[:2*secp256k1CoordinateSize]
+	default:
+		return false, nil
+	}
+
+	var r, s secp256k1.ModNScalar
+	if overflow := r.SetByteSlice(sig[:secp256k1CoordinateSize]); overflow {
+		return false, nil
+	}
+	if overflow := s.SetByteSlice(sig[secp256k1CoordinateSize:]); overflow {
+		return false, nil
+	}
+
+	return secp256k1ecdsa.NewSignature(&r, &s).Verify(hash, k.pub), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (k *secp256k1Key) MarshalJSON() ([]byte, error) {
+	r := k.raw()
+	r.KeyID = k.ID()
+	k.fill(r)
+
+	return json.Marshal(r)
+}
+
+func (k *secp256k1Key) Thumbprint(h crypto.Hash) ([]byte, error) {
+	return thumbprint(k.raw(), h)
+}
+
+func (k *secp256k1Key) raw() *rawJWK {
+	point := k.pub.SerializeUncompressed()
+
+	r := &rawJWK{
+		KeyType:   "EC",
+		Algorithm: k.Algorithm(),
+		Curve:     "secp256k1",
+		X:         base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(point[1 : 1+secp256k1CoordinateSize]),
+		Y:         base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(point[1+secp256k1CoordinateSize:]),
+	}
+	if k.HasPrivate() {
+		r.D = base64.URLEncoding.WithPadding(base64.NoPadding).EncodeToString(k.priv.Serialize())
+	}
+
+	return r
+}
@@ -0,0 +1,84 @@
+package key
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSecp256k1_Generation(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, err := ES256K()
+	Expect(err).To(BeNil())
+	Expect(key.Algorithm()).To(Equal("ES256K"))
+	Expect(key.HasPrivate()).To(BeTrue())
+	Expect(key.HasPublic()).To(BeTrue())
+
+	pub := key.Public()
+	Expect(pub.HasPrivate()).To(BeFalse())
+	Expect(pub.ID()).To(Equal(key.ID()))
+
+	sig, err := key.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+	Expect(len(sig)).To(Equal(64))
+
+	valid, err := pub.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	valid, err = pub.Verify([]byte("tampered"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+}
+
+func TestSecp256k1_Recoverable(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, err := ES256KRecoverable()
+	Expect(err).To(BeNil())
+	Expect(key.Algorithm()).To(Equal("ES256K-R"))
+
+	sig, err := key.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+	Expect(len(sig)).To(Equal(65))
+
+	valid, err := key.Public().Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	recovered, err := RecoverPublicKey([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(recovered.ID()).To(Equal(key.ID()))
+	Expect(recovered.HasPrivate()).To(BeFalse())
+
+	recovered, err = RecoverPublicKey([]byte("tampered"), sig)
+	if err == nil {
+		Expect(recovered.ID()).ToNot(Equal(key.ID()))
+	}
+}
+
+func TestSecp256k1_Serialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, err := ES256K()
+	Expect(err).To(BeNil())
+
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+	Expect(string(out)).To(ContainSubstring(`"crv":"secp256k1"`))
+	Expect(string(out)).To(ContainSubstring(`"kty":"EC"`))
+
+	back, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(back.Algorithm()).To(Equal("ES256K"))
+	Expect(back.ID()).To(Equal(key.ID()))
+	Expect(back.HasPrivate()).To(BeTrue())
+
+	sig, err := back.Sign([]byte("payload"))
+	Expect(err).To(BeNil())
+	valid, err := key.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+}
@@ -0,0 +1,104 @@
+package key
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// Set implements a JWK Set: RFC 7517 Section 5
+type Set struct {
+	Keys []Key
+}
+
+// rawSet is the serialized form of a JWK Set
+type rawSet struct {
+	Keys []json.RawMessage `json:"keys"`
+}
+
+// NewSet returns a JWK Set holding the given keys
+func NewSet(keys ...Key) *Set {
+	return &Set{
+		Keys: keys,
+	}
+}
+
+// LookupKeyID returns the key matching the given identifier
+func (s *Set) LookupKeyID(kid string) (Key, bool) {
+	for _, k := range s.Keys {
+		if k.ID() == kid {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+// LookupAlgorithm returns all keys matching the given algorithm, either the
+// key algorithm (Ed25519) or its JWK "alg" value (EdDSA)
+func (s *Set) LookupAlgorithm(alg string) []Key {
+	var result []Key
+	for _, k := range s.Keys {
+		if k.Algorithm() == alg || jwkAlgorithm(k) == alg {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// LookupUse returns all keys declaring the given public key use (sig, enc)
+func (s *Set) LookupUse(use string) []Key {
+	var result []Key
+	for _, k := range s.Keys {
+		if k.Metadata().Use == use {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// -----------------------------------------------------------------------------
+
+// MarshalJSON serializes the set as {"keys":[...]}
+func (s *Set) MarshalJSON() ([]byte, error) {
+	keys := s.Keys
+	if keys == nil {
+		keys = []Key{}
+	}
+
+	return json.Marshal(struct {
+		Keys []Key `json:"keys"`
+	}{
+		Keys: keys,
+	})
+}
+
+// UnmarshalJSON decodes a JWK Set, keys that could not be decoded (unknown
+// key type, unsupported algorithm) are ignored: RFC 7517 Section 5
+func (s *Set) UnmarshalJSON(data []byte) error {
+	var raw rawSet
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw.Keys == nil {
+		return errors.New("key: invalid JWK Set, missing keys member")
+	}
+
+	s.Keys = nil
+	for _, item := range raw.Keys {
+		k, err := FromString(item)
+		if err != nil {
+			continue
+		}
+		s.Keys = append(s.Keys, k)
+	}
+
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func jwkAlgorithm(k Key) string {
+	if r, ok := k.(interface{ raw() *rawJWK }); ok {
+		return r.raw().Algorithm
+	}
+	return ""
+}
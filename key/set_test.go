@@ -0,0 +1,70 @@
+package key
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSet_Serialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	ed, _ := Ed25519()
+	es, _ := ES256()
+
+	out, err := json.Marshal(NewSet(ed.Public(), es.Public()))
+	Expect(err).To(BeNil())
+
+	var fields map[string][]map[string]interface{}
+	Expect(json.Unmarshal(out, &fields)).To(BeNil())
+	Expect(fields["keys"]).To(HaveLen(2))
+
+	var set Set
+	Expect(json.Unmarshal(out, &set)).To(BeNil())
+	Expect(set.Keys).To(HaveLen(2))
+
+	k, ok := set.LookupKeyID(es.ID())
+	Expect(ok).To(BeTrue())
+	Expect(k.Algorithm()).To(Equal("ES256"))
+
+	_, ok = set.LookupKeyID("unknown")
+	Expect(ok).To(BeFalse())
+
+	Expect(set.LookupAlgorithm("EdDSA")).To(HaveLen(1))
+	Expect(set.LookupAlgorithm("Ed25519")).To(HaveLen(1))
+	Expect(set.LookupAlgorithm("RS256")).To(BeEmpty())
+
+	out, err = json.Marshal(NewSet())
+	Expect(err).To(BeNil())
+	Expect(string(out)).To(Equal(`{"keys":[]}`))
+}
+
+func TestSet_Deserialization(t *testing.T) {
+	RegisterTestingT(t)
+
+	var set Set
+	err := json.Unmarshal([]byte(`{"keys":[
+		{"kty":"EC","crv":"P-256","use":"sig","kid":"sig-key","x":"f83OJ3D2xF1Bg8vub9tLe1gHMzV76e8Tus9uPHvRVEU","y":"x_FEzRu9m36HLN_tue659LNpXW6pCyStikYjKIWI5a0"},
+		{"kty":"OKP","crv":"X25519","use":"enc","kid":"enc-key","x":"3p7bfXt9wbTTW2HC7OQ1Nz-DQ8hbeGdNrfx-FG-IK08"},
+		{"kty":"unknown","kid":"ignored"},
+		{"kty":"EC","crv":"P-256","kid":"broken","x":"AAAA","y":"AAAA"}
+	]}`), &set)
+	Expect(err).To(BeNil())
+	Expect(set.Keys).To(HaveLen(2))
+
+	sig := set.LookupUse("sig")
+	Expect(sig).To(HaveLen(1))
+	Expect(sig[0].ID()).To(Equal("sig-key"))
+
+	enc := set.LookupUse("enc")
+	Expect(enc).To(HaveLen(1))
+	Expect(enc[0].ID()).To(Equal("enc-key"))
+
+	// Use is preserved
+	out, err := json.Marshal(enc[0])
+	Expect(err).To(BeNil())
+	Expect(string(out)).To(ContainSubstring(`"use":"enc"`))
+
+	Expect(json.Unmarshal([]byte(`{}`), &set)).ToNot(BeNil())
+}
@@ -0,0 +1,41 @@
+package key
+
+import "crypto"
+
+// SignOptions selects a signature variant
+type SignOptions struct {
+	// Hash is the function used to pre-hash the message, the data given to
+	// SignWithOptions / VerifyWithOptions is then the message digest.
+	// crypto.SHA512 selects Ed25519ph for Ed25519 keys, ECDSA and RSA keys
+	// only accept the hash of their algorithm.
+	Hash crypto.Hash
+	// Context is a domain separation string (Ed25519ctx, Ed25519ph and Ed448
+	// only), up to 255 bytes
+	Context string
+}
+
+func (o *SignOptions) isZero() bool {
+	return o == nil || (o.Hash == 0 && len(o.Context) == 0)
+}
+
+// maxContextSize is the maximum size of an EdDSA context (RFC 8032)
+const maxContextSize = 255
+
+// digestWithOptions returns the digest to sign with the given hash function,
+// computed from the message or provided as data by pre-hashed options
+func digestWithOptions(h crypto.Hash, data []byte, opts *SignOptions) ([]byte, error) {
+	if opts.isZero() {
+		d := h.New()
+		d.Write(data)
+		return d.Sum(nil), nil
+	}
+
+	if len(opts.Context) > 0 || opts.Hash != h {
+		return nil, ErrInvalidOperationNotSupported
+	}
+	if len(data) != h.Size() {
+		return nil, ErrInvalidDigestSize
+	}
+
+	return data, nil
+}
@@ -0,0 +1,111 @@
+package key
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestSignOptions_Ed25519ctx(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 8032 - 7.2 Test Vectors for Ed25519ctx
+	k, err := FromString([]byte(`{"kty":"OKP","crv":"Ed25519","d":"AwUzTjga948UHLZm9hmfV7w0lTNaJWqVvSpVv1RmY_Y","x":"38lCXk-Wj38MKfAlnPX5rtaFHCu0rYv7hgz-4KskgpI"}`))
+	Expect(err).To(BeNil())
+
+	message, _ := hex.DecodeString("f726936d19c800494e3fdaff20b276a8")
+	expected, _ := hex.DecodeString("55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d")
+
+	sig, err := k.SignWithOptions(message, &SignOptions{Context: "foo"})
+	Expect(err).To(BeNil())
+	Expect(sig).To(Equal(expected))
+
+	valid, err := k.Public().VerifyWithOptions(message, sig, &SignOptions{Context: "foo"})
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	// Domain separation
+	valid, err = k.Public().VerifyWithOptions(message, sig, &SignOptions{Context: "bar"})
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+	valid, err = k.Public().Verify(message, sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+
+	_, err = k.SignWithOptions(message, &SignOptions{Context: strings.Repeat("a", 256)})
+	Expect(err).To(Equal(ErrInvalidContext))
+}
+
+func TestSignOptions_Ed25519ph(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 8032 - 7.3 Test Vectors for Ed25519ph
+	k, err := FromString([]byte(`{"kty":"OKP","crv":"Ed25519","d":"gz_mJAkje51i7HdYdSCRHpp1nOwdGXVbfakBuW3KPUI","x":"7Bcrk61eVjv0kyxw4SRQNMNUZ-8u_U1k6_gZaDRn4r8"}`))
+	Expect(err).To(BeNil())
+
+	digest := sha512.Sum512([]byte("abc"))
+	expected, _ := hex.DecodeString("98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406")
+
+	sig, err := k.SignWithOptions(digest[:], &SignOptions{Hash: crypto.SHA512})
+	Expect(err).To(BeNil())
+	Expect(sig).To(Equal(expected))
+
+	valid, err := k.Public().VerifyWithOptions(digest[:], sig, &SignOptions{Hash: crypto.SHA512})
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	// Pre-hashed signature with context
+	sig, err = k.SignWithOptions(digest[:], &SignOptions{Hash: crypto.SHA512, Context: "artifact"})
+	Expect(err).To(BeNil())
+	valid, err = k.Public().VerifyWithOptions(digest[:], sig, &SignOptions{Hash: crypto.SHA512, Context: "token"})
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+
+	_, err = k.SignWithOptions(digest[:32], &SignOptions{Hash: crypto.SHA512})
+	Expect(err).To(Equal(ErrInvalidDigestSize))
+	_, err = k.SignWithOptions(digest[:32], &SignOptions{Hash: crypto.SHA256})
+	Expect(err).To(Equal(ErrInvalidOperationNotSupported))
+}
+
+func TestSignOptions_Others(t *testing.T) {
+	RegisterTestingT(t)
+
+	// Ed448 context
+	ed, _ := Ed448()
+	sig, err := ed.SignWithOptions([]byte("payload"), &SignOptions{Context: "artifact"})
+	Expect(err).To(BeNil())
+	valid, err := ed.VerifyWithOptions([]byte("payload"), sig, &SignOptions{Context: "artifact"})
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+	valid, err = ed.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+
+	// Pre-hashed ECDSA, RSA and secp256k1 signatures
+	digest := sha256.Sum256([]byte("payload"))
+	for _, generate := range []func() (Key, error){ES256, RS256, PS256, ES256K} {
+		k, _ := generate()
+
+		sig, err := k.SignWithOptions(digest[:], &SignOptions{Hash: crypto.SHA256})
+		Expect(err).To(BeNil())
+		valid, err := k.Verify([]byte("payload"), sig)
+		Expect(err).To(BeNil())
+		Expect(valid).To(BeTrue())
+
+		_, err = k.SignWithOptions([]byte("payload"), &SignOptions{Context: "artifact"})
+		Expect(err).To(Equal(ErrInvalidOperationNotSupported))
+		_, err = k.SignWithOptions(digest[:], &SignOptions{Hash: crypto.SHA384})
+		Expect(err).To(Equal(ErrInvalidOperationNotSupported))
+	}
+
+	hs, _ := HS256()
+	_, err = hs.SignWithOptions(digest[:], &SignOptions{Hash: crypto.SHA256})
+	Expect(err).To(Equal(ErrInvalidOperationNotSupported))
+	_, err = hs.SignWithOptions([]byte("payload"), &SignOptions{})
+	Expect(err).To(BeNil())
+}
@@ -0,0 +1,113 @@
+package key
+
+import (
+	"crypto"
+	"io"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+)
+
+// Signer wraps the private key as a crypto.Signer, whose Public() returns the
+// native public key (ed25519.PublicKey, *ecdsa.PublicKey, *rsa.PublicKey, ...).
+// Signatures are produced in the standard library format (ASN.1 for ECDSA),
+// which could differ from the JWS one returned by Key.Sign.
+func Signer(k Key) (crypto.Signer, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !Permits(k, OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+
+	ck, ok := k.(cryptoKey)
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	var s crypto.Signer
+	switch priv := ck.cryptoPrivateKey().(type) {
+	case *secp256k1.PrivateKey:
+		s = &secp256k1Signer{priv: priv}
+	case crypto.Signer:
+		s = priv
+	default:
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	return &keySigner{key: k, signer: s}, nil
+}
+
+// Decrypter wraps the private key as a crypto.Decrypter, only RSA keys are
+// supported
+func Decrypter(k Key) (crypto.Decrypter, error) {
+	rk, ok := k.(*rsaKey)
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+	if !rk.HasPrivate() {
+		return nil, ErrInvalidOperationDecryptWithoutPrivateKey
+	}
+	if !Permits(k, OperationDecrypt) {
+		return nil, operationNotPermitted(k, OperationDecrypt)
+	}
+
+	return &keyDecrypter{key: k, decrypter: rk.priv}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// keySigner checks that the key is still allowed to sign before each
+// signature, as its metadata could change and its private material could be
+// destroyed after the adapter creation
+type keySigner struct {
+	key    Key
+	signer crypto.Signer
+}
+
+func (s *keySigner) Public() crypto.PublicKey {
+	return s.signer.Public()
+}
+
+func (s *keySigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	// The captured private key is wiped when the key is destroyed
+	if !s.key.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !Permits(s.key, OperationSign) {
+		return nil, operationNotPermitted(s.key, OperationSign)
+	}
+	return s.signer.Sign(rand, digest, opts)
+}
+
+type keyDecrypter struct {
+	key       Key
+	decrypter crypto.Decrypter
+}
+
+func (d *keyDecrypter) Public() crypto.PublicKey {
+	return d.decrypter.Public()
+}
+
+func (d *keyDecrypter) Decrypt(rand io.Reader, msg []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	if !d.key.HasPrivate() {
+		return nil, ErrInvalidOperationDecryptWithoutPrivateKey
+	}
+	if !Permits(d.key, OperationDecrypt) {
+		return nil, operationNotPermitted(d.key, OperationDecrypt)
+	}
+	return d.decrypter.Decrypt(rand, msg, opts)
+}
+
+// secp256k1Signer produces ASN.1 DER encoded signatures of the given digest
+type secp256k1Signer struct {
+	priv *secp256k1.PrivateKey
+}
+
+func (s *secp256k1Signer) Public() crypto.PublicKey {
+	return s.priv.PubKey()
+}
+
+func (s *secp256k1Signer) Sign(_ io.Reader, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	return secp256k1ecdsa.Sign(s.priv, digest).Serialize(), nil
+}
@@ -0,0 +1,152 @@
+package key
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	secp256k1ecdsa "github.com/decred/dcrd/dcrec/secp256k1/v4/ecdsa"
+	. "github.com/onsi/gomega"
+)
+
+func TestSigner_X509Certificate(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, generate := range []func() (Key, error){Ed25519, ES256, ES512, RS256, PS256} {
+		k, _ := generate()
+
+		signer, err := Signer(k)
+		Expect(err).To(BeNil())
+		Expect(signer.Public()).To(Equal(k.(cryptoKey).cryptoPublicKey()))
+
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: k.ID()},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageCertSign,
+			IsCA:         true,
+
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+		Expect(err).To(BeNil())
+
+		cert, err := x509.ParseCertificate(der)
+		Expect(err).To(BeNil())
+		Expect(cert.CheckSignatureFrom(cert)).To(BeNil())
+	}
+}
+
+func TestSigner_Others(t *testing.T) {
+	RegisterTestingT(t)
+
+	// Ed448 signs the message itself
+	ed, _ := Ed448()
+	signer, err := Signer(ed)
+	Expect(err).To(BeNil())
+	sig, err := signer.Sign(rand.Reader, []byte("payload"), crypto.Hash(0))
+	Expect(err).To(BeNil())
+	valid, err := ed.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	// secp256k1 produces ASN.1 signatures of the digest
+	k1, _ := ES256K()
+	signer, err = Signer(k1)
+	Expect(err).To(BeNil())
+	digest := sha256.Sum256([]byte("payload"))
+	sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	Expect(err).To(BeNil())
+	parsed, err := secp256k1ecdsa.ParseDERSignature(sig)
+	Expect(err).To(BeNil())
+	Expect(parsed.Verify(digest[:], signer.Public().(*secp256k1.PublicKey))).To(BeTrue())
+
+	// Unsupported keys
+	hs, _ := HS256()
+	_, err = Signer(hs)
+	Expect(err).To(Equal(ErrAlgorithmNotSupported))
+	x, _ := X25519()
+	_, err = Signer(x)
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+
+	_, err = Signer(ed.Public())
+	Expect(err).To(Equal(ErrInvalidOperationCouldSignWithoutPrivateKey))
+}
+
+func TestSigner_Restrictions(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, _ := ES256()
+	signer, err := Signer(k)
+	Expect(err).To(BeNil())
+
+	k.Metadata().KeyOperations = []string{OperationVerify}
+	digest := sha256.Sum256([]byte("payload"))
+	_, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+
+	_, err = Signer(k)
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+}
+
+func TestSigner_Destroyed(t *testing.T) {
+	RegisterTestingT(t)
+
+	for _, generate := range []func() (Key, error){Ed25519, Ed448, ES256, RS256, ES256K} {
+		k, _ := generate()
+		signer, err := Signer(k)
+		Expect(err).To(BeNil())
+
+		Destroy(k)
+		digest := sha256.Sum256([]byte("payload"))
+		_, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+		Expect(err).To(Equal(ErrInvalidOperationCouldSignWithoutPrivateKey), k.Algorithm())
+	}
+
+	rs, _ := RS256()
+	decrypter, err := Decrypter(rs)
+	Expect(err).To(BeNil())
+
+	pub := decrypter.Public().(*rsa.PublicKey)
+	ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, []byte("secret"), nil)
+	Expect(err).To(BeNil())
+
+	Destroy(rs)
+	_, err = decrypter.Decrypt(rand.Reader, ciphertext, &rsa.OAEPOptions{Hash: crypto.SHA256})
+	Expect(err).To(Equal(ErrInvalidOperationDecryptWithoutPrivateKey))
+}
+
+func TestDecrypter(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, _ := RS256()
+	decrypter, err := Decrypter(k)
+	Expect(err).To(BeNil())
+
+	pub := decrypter.Public().(*rsa.PublicKey)
+	ciphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, []byte("secret"), nil)
+	Expect(err).To(BeNil())
+
+	plaintext, err := decrypter.Decrypt(rand.Reader, ciphertext, &rsa.OAEPOptions{Hash: crypto.SHA256})
+	Expect(err).To(BeNil())
+	Expect(plaintext).To(Equal([]byte("secret")))
+
+	k.Metadata().Use = "sig"
+	_, err = decrypter.Decrypt(rand.Reader, ciphertext, &rsa.OAEPOptions{Hash: crypto.SHA256})
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+
+	_, err = Decrypter(k.Public())
+	Expect(err).To(Equal(ErrInvalidOperationDecryptWithoutPrivateKey))
+
+	es, _ := ES256()
+	_, err = Decrypter(es)
+	Expect(err).To(Equal(ErrAlgorithmNotSupported))
+}
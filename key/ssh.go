@@ -0,0 +1,134 @@
+package key
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+
+	"golang.org/x/crypto/ed25519"
+	"golang.org/x/crypto/ssh"
+)
+
+// MarshalAuthorizedKey encodes the public part of the key as an OpenSSH
+// authorized_keys line, using the key identifier as comment
+func MarshalAuthorizedKey(k Key) ([]byte, error) {
+	pub, err := sshPublicKey(k)
+	if err != nil {
+		return nil, err
+	}
+
+	line := bytes.TrimSuffix(ssh.MarshalAuthorizedKey(pub), []byte("\n"))
+	line = append(line, ' ')
+	line = append(line, k.ID()...)
+
+	return append(line, '\n'), nil
+}
+
+// FromAuthorizedKey builds a public Key instance of the given algorithm (see
+// FromPEM) from an OpenSSH authorized_keys line
+func FromAuthorizedKey(input []byte, alg string) (Key, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey(input)
+	if err != nil {
+		return nil, err
+	}
+
+	cpk, ok := pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	return fromCryptoPublicKey(cpk.CryptoPublicKey(), alg)
+}
+
+// MarshalOpenSSHPrivateKey encodes the private key as an OpenSSH private key
+// file, encrypted with the passphrase when it is not empty
+func MarshalOpenSSHPrivateKey(k Key, passphrase []byte) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationExportWithoutPrivateKey
+	}
+	if _, err := sshPublicKey(k); err != nil {
+		return nil, err
+	}
+
+	priv := k.(cryptoKey).cryptoPrivateKey()
+
+	var (
+		block *pem.Block
+		err   error
+	)
+	if len(passphrase) > 0 {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, k.ID(), passphrase)
+	} else {
+		block, err = ssh.MarshalPrivateKey(priv, k.ID())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(block), nil
+}
+
+// FromOpenSSHPrivateKey builds a Key instance of the given algorithm (see
+// FromPEM) from an OpenSSH private key file. The passphrase is only used when
+// the file is encrypted.
+func FromOpenSSHPrivateKey(input, passphrase []byte, alg string) (Key, error) {
+	var (
+		priv interface{}
+		err  error
+	)
+	if len(passphrase) > 0 {
+		priv, err = ssh.ParseRawPrivateKeyWithPassphrase(input, passphrase)
+	} else {
+		priv, err = ssh.ParseRawPrivateKey(input)
+	}
+
+	var missing *ssh.PassphraseMissingError
+	switch {
+	case errors.As(err, &missing):
+		return nil, ErrEncryptedPEM
+	case errors.Is(err, x509.IncorrectPasswordError):
+		return nil, ErrIncorrectPassword
+	case err != nil:
+		return nil, err
+	}
+
+	// Ed25519 private keys are returned by reference
+	if pk, ok := priv.(*ed25519.PrivateKey); ok {
+		priv = *pk
+	}
+
+	return fromCryptoPrivateKey(priv, alg)
+}
+
+// SSHSigner returns an ssh.Signer backed by the private key, usable for SSH
+// authentication or to sign host and user certificates
+func SSHSigner(k Key) (ssh.Signer, error) {
+	if _, err := sshPublicKey(k); err != nil {
+		return nil, err
+	}
+
+	signer, err := Signer(k)
+	if err != nil {
+		return nil, err
+	}
+
+	return ssh.NewSignerFromSigner(signer)
+}
+
+// -----------------------------------------------------------------------------
+
+// sshPublicKey returns the SSH representation of the public key, only
+// Ed25519, ECDSA and RSA keys are supported by the SSH protocol
+func sshPublicKey(k Key) (ssh.PublicKey, error) {
+	switch k.(type) {
+	case *ed25519Key, *ecdsaKey, *rsaKey:
+	default:
+		return nil, ErrAlgorithmNotSupported
+	}
+	if !k.HasPublic() {
+		return nil, ErrInvalidOperationCouldVerifyWithoutPublicKey
+	}
+
+	return ssh.NewPublicKey(k.(cryptoKey).cryptoPublicKey())
+}
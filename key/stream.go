@@ -0,0 +1,195 @@
+package key
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha512"
+	"hash"
+	"io"
+)
+
+// streamer is implemented by keys able to sign a message read as a stream:
+// the stream is written to the hash returned by streamHash, and its sum is
+// then signed or verified.
+type streamer interface {
+	streamHash() hash.Hash
+	signStream(sum []byte, opts *SignOptions) ([]byte, error)
+	verifyStream(sum, sig []byte, opts *SignOptions) (bool, error)
+}
+
+// SignReader signs the message read from r without buffering it. ECDSA, RSA,
+// secp256k1 and HMAC signatures are identical to the Sign ones, Ed25519 and
+// Ed448 keys produce pre-hashed signatures (Ed25519ph, Ed448ph) which must be
+// verified with VerifyReader. opts only carries the EdDSA context, and could
+// be nil.
+func SignReader(k Key, r io.Reader, opts *SignOptions) ([]byte, error) {
+	s, ok := k.(streamer)
+	if !ok {
+		return nil, ErrInvalidOperationNotSupported
+	}
+
+	// Fail before reading the whole stream
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !Permits(k, OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+
+	h := s.streamHash()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+
+	return s.signStream(h.Sum(nil), opts)
+}
+
+// VerifyReader checks a signature produced by SignReader over the message
+// read from r
+func VerifyReader(k Key, r io.Reader, sig []byte, opts *SignOptions) (bool, error) {
+	s, ok := k.(streamer)
+	if !ok {
+		return false, ErrInvalidOperationNotSupported
+	}
+
+	// Fail before reading the whole stream
+	if !k.HasPublic() && !k.HasPrivate() {
+		return false, ErrInvalidOperationCouldVerifyWithoutPublicKey
+	}
+	if !Permits(k, OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+
+	h := s.streamHash()
+	if _, err := io.Copy(h, r); err != nil {
+		return false, err
+	}
+
+	return s.verifyStream(h.Sum(nil), sig, opts)
+}
+
+// -----------------------------------------------------------------------------
+
+// streamOptions returns the pre-hashed options for a digest computed with h
+func streamOptions(h crypto.Hash, opts *SignOptions) *SignOptions {
+	o := &SignOptions{Hash: h}
+	if opts != nil {
+		if opts.Hash != 0 && opts.Hash != h {
+			// Let the key reject the mismatching hash
+			o.Hash = opts.Hash
+		}
+		o.Context = opts.Context
+	}
+	return o
+}
+
+func (k *ed25519Key) streamHash() hash.Hash {
+	return sha512.New()
+}
+
+func (k *ed25519Key) signStream(sum []byte, opts *SignOptions) ([]byte, error) {
+	return k.SignWithOptions(sum, streamOptions(crypto.SHA512, opts))
+}
+
+func (k *ed25519Key) verifyStream(sum, sig []byte, opts *SignOptions) (bool, error) {
+	return k.VerifyWithOptions(sum, sig, streamOptions(crypto.SHA512, opts))
+}
+
+func (k *ed448Key) streamHash() hash.Hash {
+	return newEd448phHash()
+}
+
+func (k *ed448Key) signStream(sum []byte, opts *SignOptions) ([]byte, error) {
+	ctx, err := ed448Context(opts)
+	if err != nil {
+		return nil, err
+	}
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+	return ed448SignPh(k.priv, sum, ctx)
+}
+
+func (k *ed448Key) verifyStream(sum, sig []byte, opts *SignOptions) (bool, error) {
+	ctx, err := ed448Context(opts)
+	if err != nil {
+		return false, err
+	}
+	if !k.HasPublic() {
+		return false, ErrInvalidOperationCouldVerifyWithoutPublicKey
+	}
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+	return ed448VerifyPh(k.pub, sum, sig, ctx), nil
+}
+
+func (k *ecdsaKey) streamHash() hash.Hash {
+	return k.params.hash.New()
+}
+
+func (k *ecdsaKey) signStream(sum []byte, opts *SignOptions) ([]byte, error) {
+	return k.SignWithOptions(sum, streamOptions(k.params.hash, opts))
+}
+
+func (k *ecdsaKey) verifyStream(sum, sig []byte, opts *SignOptions) (bool, error) {
+	return k.VerifyWithOptions(sum, sig, streamOptions(k.params.hash, opts))
+}
+
+func (k *rsaKey) streamHash() hash.Hash {
+	return k.params.hash.New()
+}
+
+func (k *rsaKey) signStream(sum []byte, opts *SignOptions) ([]byte, error) {
+	return k.SignWithOptions(sum, streamOptions(k.params.hash, opts))
+}
+
+func (k *rsaKey) verifyStream(sum, sig []byte, opts *SignOptions) (bool, error) {
+	return k.VerifyWithOptions(sum, sig, streamOptions(k.params.hash, opts))
+}
+
+func (k *secp256k1Key) streamHash() hash.Hash {
+	return crypto.SHA256.New()
+}
+
+func (k *secp256k1Key) signStream(sum []byte, opts *SignOptions) ([]byte, error) {
+	return k.SignWithOptions(sum, streamOptions(crypto.SHA256, opts))
+}
+
+func (k *secp256k1Key) verifyStream(sum, sig []byte, opts *SignOptions) (bool, error) {
+	return k.VerifyWithOptions(sum, sig, streamOptions(crypto.SHA256, opts))
+}
+
+// The HMAC key streams the message in the MAC itself
+func (k *hmacKey) streamHash() hash.Hash {
+	return hmac.New(k.params.hash.New, k.secret)
+}
+
+func (k *hmacKey) signStream(sum []byte, opts *SignOptions) ([]byte, error) {
+	if !k.HasPrivate() {
+		return nil, ErrInvalidOperationCouldSignWithoutPrivateKey
+	}
+	if !k.permits(OperationSign) {
+		return nil, operationNotPermitted(k, OperationSign)
+	}
+	if !opts.isZero() {
+		return nil, ErrInvalidOperationNotSupported
+	}
+	return sum, nil
+}
+
+func (k *hmacKey) verifyStream(sum, sig []byte, opts *SignOptions) (bool, error) {
+	if !k.HasPrivate() {
+		return false, ErrInvalidOperationCouldVerifyWithoutSecretKey
+	}
+	if !k.permits(OperationVerify) {
+		return false, operationNotPermitted(k, OperationVerify)
+	}
+	if !opts.isZero() {
+		return false, ErrInvalidOperationNotSupported
+	}
+	return hmac.Equal(sum, sig), nil
+}
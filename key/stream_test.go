@@ -0,0 +1,106 @@
+package key
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha512"
+	"testing"
+
+	"github.com/cloudflare/circl/sign/ed448"
+	. "github.com/onsi/gomega"
+)
+
+func TestStream_SignVerify(t *testing.T) {
+	RegisterTestingT(t)
+
+	artifact := bytes.Repeat([]byte("release artifact "), 64*1024)
+
+	forEachKey(func(alg string, k Key) {
+		sig, err := SignReader(k, bytes.NewReader(artifact), nil)
+		if !CanSign(k) {
+			Expect(err).To(Equal(ErrInvalidOperationNotSupported), alg)
+			return
+		}
+		Expect(err).To(BeNil(), alg)
+
+		verifier := k.Public()
+		if _, ok := k.(*hmacKey); ok {
+			verifier = k
+		}
+
+		valid, err := VerifyReader(verifier, bytes.NewReader(artifact), sig, nil)
+		Expect(err).To(BeNil(), alg)
+		Expect(valid).To(BeTrue(), alg)
+
+		valid, err = VerifyReader(verifier, bytes.NewReader(artifact[1:]), sig, nil)
+		Expect(err).To(BeNil(), alg)
+		Expect(valid).To(BeFalse(), alg)
+
+		// Only EdDSA switches to its pre-hash variant, the other algorithms
+		// produce regular signatures
+		valid, err = verifier.Verify(artifact, sig)
+		Expect(err).To(BeNil(), alg)
+		switch k.(type) {
+		case *ed25519Key, *ed448Key:
+			Expect(valid).To(BeFalse(), alg)
+		default:
+			Expect(valid).To(BeTrue(), alg)
+		}
+	})
+}
+
+func TestStream_PreHash(t *testing.T) {
+	RegisterTestingT(t)
+
+	artifact := bytes.Repeat([]byte("release artifact "), 1024)
+	opts := &SignOptions{Context: "artifact"}
+
+	// Ed25519ph
+	ed, _ := Ed25519()
+	sig, err := SignReader(ed, bytes.NewReader(artifact), opts)
+	Expect(err).To(BeNil())
+
+	digest := sha512.Sum512(artifact)
+	valid, err := ed.VerifyWithOptions(digest[:], sig, &SignOptions{Hash: crypto.SHA512, Context: "artifact"})
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	valid, err = VerifyReader(ed, bytes.NewReader(artifact), sig, &SignOptions{Context: "token"})
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+
+	// Ed448ph must match the reference implementation
+	k, _ := Ed448()
+	sig, err = SignReader(k, bytes.NewReader(artifact), opts)
+	Expect(err).To(BeNil())
+	Expect(sig).To(Equal(ed448.SignPh(k.(*ed448Key).priv, artifact, "artifact")))
+
+	ref := ed448.SignPh(k.(*ed448Key).priv, artifact, "")
+	valid, err = VerifyReader(k.Public(), bytes.NewReader(artifact), ref, nil)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	valid, err = VerifyReader(k.Public(), bytes.NewReader(artifact), sig, nil)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeFalse())
+}
+
+func TestStream_Errors(t *testing.T) {
+	RegisterTestingT(t)
+
+	ed, _ := Ed25519()
+	_, err := SignReader(ed.Public(), bytes.NewReader(nil), nil)
+	Expect(err).To(Equal(ErrInvalidOperationCouldSignWithoutPrivateKey))
+
+	ed.Metadata().KeyOperations = []string{OperationVerify}
+	_, err = SignReader(ed, bytes.NewReader(nil), nil)
+	Expect(err).To(BeAssignableToTypeOf(&OperationNotPermittedError{}))
+
+	x, _ := X25519()
+	_, err = SignReader(x, bytes.NewReader(nil), nil)
+	Expect(err).To(Equal(ErrInvalidOperationNotSupported))
+
+	es, _ := ES256()
+	_, err = SignReader(es, bytes.NewReader(nil), &SignOptions{Context: "artifact"})
+	Expect(err).To(Equal(ErrInvalidOperationNotSupported))
+}
@@ -0,0 +1,40 @@
+package key
+
+import (
+	"crypto"
+	"encoding/json"
+)
+
+// thumbprint computes the RFC 7638 JWK thumbprint using only the required
+// members of the key type, serialized with lexicographically ordered names
+// and without whitespace.
+func thumbprint(raw *rawJWK, h crypto.Hash) ([]byte, error) {
+	if !h.Available() {
+		return nil, ErrHashNotAvailable
+	}
+
+	var members map[string]string
+	switch raw.KeyType {
+	case "EC":
+		members = map[string]string{"crv": raw.Curve, "kty": raw.KeyType, "x": raw.X, "y": raw.Y}
+	case "RSA":
+		members = map[string]string{"e": raw.E, "kty": raw.KeyType, "n": raw.N}
+	case "oct":
+		members = map[string]string{"k": raw.K, "kty": raw.KeyType}
+	case "OKP":
+		// RFC 8037 Section 2
+		members = map[string]string{"crv": raw.Curve, "kty": raw.KeyType, "x": raw.X}
+	default:
+		return nil, ErrAlgorithmNotSupported
+	}
+
+	// encoding/json sorts map keys, base64url values need no escaping
+	payload, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := h.New()
+	hasher.Write(payload)
+	return hasher.Sum(nil), nil
+}
@@ -0,0 +1,71 @@
+package key
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestThumbprint_RFC7638(t *testing.T) {
+	RegisterTestingT(t)
+
+	// RFC 7638 Section 3.1 key
+	key, err := FromString([]byte(`{
+		"kty": "RSA",
+		"n": "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		"e": "AQAB",
+		"alg": "RS256",
+		"kid": "2011-04-29"
+	}`))
+	Expect(err).To(BeNil())
+	Expect(key.ID()).To(Equal("2011-04-29"))
+
+	tp, err := key.Thumbprint(crypto.SHA256)
+	Expect(err).To(BeNil())
+	Expect(base64.RawURLEncoding.EncodeToString(tp)).To(Equal("NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"))
+
+	// RFC 8037 Appendix A.3
+	key, err = FromString([]byte(`{
+		"kty": "OKP",
+		"crv": "Ed25519",
+		"d": "nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",
+		"x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	}`))
+	Expect(err).To(BeNil())
+
+	tp, err = key.Public().Thumbprint(crypto.SHA256)
+	Expect(err).To(BeNil())
+	Expect(base64.RawURLEncoding.EncodeToString(tp)).To(Equal("kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"))
+}
+
+func TestIDStrategies(t *testing.T) {
+	RegisterTestingT(t)
+
+	key, _ := Ed25519()
+	libtrust := key.ID()
+
+	Expect(AssignID(key, ThumbprintID(crypto.SHA256))).To(BeNil())
+	Expect(key.ID()).ToNot(Equal(libtrust))
+	Expect(key.Public().ID()).To(Equal(key.ID()))
+
+	// Assigned identifier survives serialization
+	out, err := json.Marshal(key)
+	Expect(err).To(BeNil())
+	back, err := FromString(out)
+	Expect(err).To(BeNil())
+	Expect(back.ID()).To(Equal(key.ID()))
+
+	Expect(AssignID(key, RandomID)).To(BeNil())
+	Expect(key.ID()).To(MatchRegexp(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`))
+
+	Expect(AssignID(key, StaticID("my-key"))).To(BeNil())
+	Expect(key.ID()).To(Equal("my-key"))
+
+	Expect(AssignID(key, LibtrustID)).To(BeNil())
+	Expect(key.ID()).To(Equal(libtrust))
+
+	Expect(AssignID(key, StaticID(""))).To(Equal(ErrInvalidKeyID))
+}
@@ -3,6 +3,7 @@ package keystore
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"go.zenithar.org/keystore/key"
@@ -19,12 +20,18 @@ type Expirable interface {
 type KeyStore interface {
 	All() ([]key.Key, error)
 	OnlyPublicKeys() ([]key.Key, error)
+	PublicKeySet() (*key.Set, error)
 	Add(key.Key) error
 	AddWithExpiration(key.Key, time.Duration) error
+	AddPending(key.Key) error
 	Get(string) (key.Key, error)
 	Remove(string) error
+	Status(string) (*Status, error)
+	Transition(string, State) error
+	ByState(State) ([]key.Key, error)
 	RotateKeys(context.Context) error
 	Pick() (key.Key, error)
+	PickContext(context.Context) (key.Key, error)
 	Generate() (key.Key, error)
 }
 
@@ -33,11 +40,50 @@ type KeyGenerator func() (key.Key, error)
 
 // -----------------------------------------------------------------------------
 
+// issuedAt returns the key creation date, or now for keys without one
+func issuedAt(k key.Key) time.Time {
+	if created := k.CreatedAt(); !created.IsZero() {
+		return created.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// ageExpiration returns the date a key without explicit expiration reaches
+// the maximal age, the key never expires when maxAge is not set or its
+// creation date is unknown
+func ageExpiration(k key.Key, maxAge time.Duration) (time.Time, bool) {
+	if maxAge <= 0 || k.CreatedAt().IsZero() {
+		return time.Time{}, false
+	}
+	return k.CreatedAt().Add(maxAge), true
+}
+
+// cloneKey returns a copy of a stored key handed out by Pick, so that the
+// removal of the stored key does not wipe the key held by the caller, which
+// owns the copy and destroys it
+func cloneKey(k key.Key) (key.Key, error) {
+	c, err := key.Clone(k)
+	if err != nil {
+		return nil, fmt.Errorf("keystore: Unable to copy key %v", err)
+	}
+	return c, nil
+}
+
+// -----------------------------------------------------------------------------
+
 var (
 	// ErrNotImplemented is raised when calling not implemented method
 	ErrNotImplemented = errors.New("keystore: Method not implemented")
 	// ErrKeyNotFound is raised when trying to get inexistant key from keystore
 	ErrKeyNotFound = errors.New("keystore: Key not found")
+	// ErrKeyAlreadyExists is raised when adding a key with an identifier already known by the keystore
+	ErrKeyAlreadyExists = errors.New("keystore: Key already exists")
+	// ErrKeyIDCollision is raised when the ID strategy assigns an already known identifier to a generated key
+	ErrKeyIDCollision = errors.New("keystore: ID strategy assigned an already known key identifier")
+	// ErrKeyRevoked is raised when trying to get a revoked key from keystore
+	ErrKeyRevoked = errors.New("keystore: Key has been revoked")
+	// ErrNoSigningKey is raised when no key of the keystore is able to sign
+	ErrNoSigningKey = errors.New("keystore: No key available for signing")
 	// ErrGeneratorNeedPositiveValueAboveOne is raised when caller gives a value under 1 as count
 	ErrGeneratorNeedPositiveValueAboveOne = errors.New("keystore: Key generation count needs positive above 1 value as count")
 )
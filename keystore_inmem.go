@@ -13,20 +13,40 @@ type inMemoryKeyStore struct {
 	sync.RWMutex
 
 	generator KeyGenerator
+	opts      *options
 	store     map[string]key.Key
+	states    map[string]*keyState
 	keys      []string
-	count     int
-	pick      int
+	now       func() time.Time
+
+	// nextRotation throttles RotateKeys
+	nextRotation time.Time
+}
+
+// keyState tracks the lifecycle of a key
+type keyState struct {
+	status *Status
+	// exp is the expiration date of a key added with AddWithExpiration
+	exp time.Time
 }
 
-// NewInMemory returns an in-memory map based keystore
-func NewInMemory(generator KeyGenerator) (KeyStore, error) {
+// NewInMemory returns an in-memory map based keystore. The keystore owns the
+// added keys and wipes them on removal. Get, All and ByState return their
+// public parts, Pick returns a copy of the private key that the caller must
+// wipe with key.Destroy once done, which also releases its locked memory.
+func NewInMemory(generator KeyGenerator, opts ...Option) (KeyStore, error) {
+	o := applyOptions(opts)
+	if err := o.rotation.validate(); err != nil {
+		return nil, err
+	}
+
 	store := make(map[string]key.Key)
 	return &inMemoryKeyStore{
 		store:     store,
+		states:    make(map[string]*keyState),
 		generator: generator,
-		pick:      0,
-		count:     0,
+		opts:      o,
+		now:       time.Now,
 	}, nil
 }
 
@@ -37,6 +57,18 @@ func (ks *inMemoryKeyStore) Generate() (key.Key, error) {
 		return nil, fmt.Errorf("keystore: Key generation error %v", err)
 	}
 
+	if err := key.AssignID(k, ks.opts.idStrategy); err != nil {
+		return nil, fmt.Errorf("keystore: Key identifier assignment error %v", err)
+	}
+
+	// The key could never be added
+	ks.RLock()
+	_, known := ks.store[k.ID()]
+	ks.RUnlock()
+	if known {
+		return nil, ErrKeyIDCollision
+	}
+
 	return k, nil
 }
 
@@ -46,7 +78,7 @@ func (ks *inMemoryKeyStore) All() ([]key.Key, error) {
 
 	var result []key.Key
 	for _, i := range ks.store {
-		result = append(result, i)
+		result = append(result, i.Public())
 	}
 	return result, nil
 }
@@ -56,32 +88,89 @@ func (ks *inMemoryKeyStore) OnlyPublicKeys() ([]key.Key, error) {
 	defer ks.RUnlock()
 
 	var result []key.Key
-	for _, i := range ks.store {
-		result = append(result, i.Public())
+	for kid, i := range ks.store {
+		// Skip revoked keys
+		if !ks.states[kid].status.State.Verifiable() {
+			continue
+		}
+		// Skip keys without public part (symmetric keys)
+		if pub := i.Public(); pub.HasPublic() {
+			result = append(result, pub)
+		}
 	}
 	return result, nil
 }
 
+func (ks *inMemoryKeyStore) PublicKeySet() (*key.Set, error) {
+	keys, err := ks.OnlyPublicKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return key.NewSet(keys...), nil
+}
+
 func (ks *inMemoryKeyStore) Pick() (key.Key, error) {
-	// Round robin
-	ks.pick = (ks.pick + 1) % (len(ks.keys))
-	return ks.store[ks.keys[ks.pick]], nil
+	return ks.PickContext(context.Background())
+}
+
+func (ks *inMemoryKeyStore) PickContext(ctx context.Context) (key.Key, error) {
+	ks.RLock()
+	defer ks.RUnlock()
+
+	now := ks.now().UTC()
+
+	// Skip inactive keys, expired keys and keys that could not sign
+	var candidates []key.Key
+	for _, kid := range ks.keys {
+		if k := ks.store[kid]; ks.usable(kid, now) && key.CanSign(k) {
+			candidates = append(candidates, k)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	// Candidates could not be wiped by a removal while the lock is held
+	k, err := ks.opts.selector.Select(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	return cloneKey(k)
 }
 
 func (ks *inMemoryKeyStore) Add(k key.Key) error {
+	if err := ks.lockMemory(k); err != nil {
+		return err
+	}
+
 	ks.Lock()
-	ks.store[k.ID()] = k
-	ks.Unlock()
+	defer ks.Unlock()
 
-	return nil
+	return ks.add(k, StateActive, time.Time{})
 }
 
 func (ks *inMemoryKeyStore) AddWithExpiration(k key.Key, exp time.Duration) error {
+	if err := ks.lockMemory(k); err != nil {
+		return err
+	}
+
 	ks.Lock()
-	ks.store[k.ID()] = k
-	ks.Unlock()
+	defer ks.Unlock()
 
-	return nil
+	return ks.add(k, StateActive, ks.now().UTC().Add(exp))
+}
+
+func (ks *inMemoryKeyStore) AddPending(k key.Key) error {
+	if err := ks.lockMemory(k); err != nil {
+		return err
+	}
+
+	ks.Lock()
+	defer ks.Unlock()
+
+	return ks.add(k, StatePending, time.Time{})
 }
 
 func (ks *inMemoryKeyStore) Get(id string) (key.Key, error) {
@@ -91,16 +180,140 @@ func (ks *inMemoryKeyStore) Get(id string) (key.Key, error) {
 	if _, ok := ks.store[id]; !ok {
 		return nil, ErrKeyNotFound
 	}
-	return ks.store[id], nil
+	// Retired keys still verify signatures
+	if !ks.states[id].status.State.Verifiable() {
+		return nil, ErrKeyRevoked
+	}
+	return ks.store[id].Public(), nil
 }
 
 func (ks *inMemoryKeyStore) Remove(id string) error {
 	ks.Lock()
-	delete(ks.store, id)
+	if k, ok := ks.store[id]; ok {
+		delete(ks.store, id)
+		delete(ks.states, id)
+		for i, kid := range ks.keys {
+			if kid == id {
+				ks.keys = append(ks.keys[:i], ks.keys[i+1:]...)
+				break
+			}
+		}
+
+		// Wipe the private material of the removed key
+		key.Destroy(k)
+	}
 	ks.Unlock()
 	return nil
 }
 
+func (ks *inMemoryKeyStore) Status(id string) (*Status, error) {
+	ks.RLock()
+	defer ks.RUnlock()
+
+	state, ok := ks.states[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return state.status.clone(), nil
+}
+
+func (ks *inMemoryKeyStore) Transition(id string, to State) error {
+	ks.Lock()
+	state, ok := ks.states[id]
+	if !ok {
+		ks.Unlock()
+		return ErrKeyNotFound
+	}
+	if err := state.status.transition(to, ks.now()); err != nil {
+		ks.Unlock()
+		return err
+	}
+	ks.Unlock()
+
+	// Destroyed keys are wiped and removed
+	if to == StateDestroyed {
+		return ks.Remove(id)
+	}
+
+	return nil
+}
+
+func (ks *inMemoryKeyStore) ByState(state State) ([]key.Key, error) {
+	ks.RLock()
+	defer ks.RUnlock()
+
+	var result []key.Key
+	for _, kid := range ks.keys {
+		if ks.states[kid].status.State == state {
+			result = append(result, ks.store[kid].Public())
+		}
+	}
+	return result, nil
+}
+
+// RotateKeys applies the rotation policy, at most once per CheckInterval as
+// the Vault keystore does
 func (ks *inMemoryKeyStore) RotateKeys(ctx context.Context) error {
+	now := ks.now().UTC()
+
+	ks.Lock()
+	if now.Before(ks.nextRotation) {
+		// Rotation already done
+		ks.Unlock()
+		return nil
+	}
+	ks.nextRotation = now.Add(ks.opts.rotation.CheckInterval)
+
+	entries := make([]rotationEntry, 0, len(ks.keys))
+	for _, kid := range ks.keys {
+		state := ks.states[kid]
+		entries = append(entries, rotationEntry{
+			key:    ks.store[kid],
+			status: state.status.clone(),
+			exp:    state.exp,
+		})
+	}
+	ks.Unlock()
+
+	return applyRotation(ctx, ks, ks.opts.rotation.plan(entries, now))
+}
+
+// -----------------------------------------------------------------------------
+
+// add registers the key in the given state, caller must hold the lock
+func (ks *inMemoryKeyStore) add(k key.Key, state State, exp time.Time) error {
+	// Check if key already exists, whatever its state
+	if _, ok := ks.store[k.ID()]; ok {
+		return ErrKeyAlreadyExists
+	}
+
+	ks.keys = append(ks.keys, k.ID())
+	ks.store[k.ID()] = k
+	ks.states[k.ID()] = &keyState{
+		status: newStatus(state, ks.now()),
+		exp:    exp,
+	}
+
+	return nil
+}
+
+// usable returns true for active keys not yet expired, caller must hold the
+// lock
+func (ks *inMemoryKeyStore) usable(kid string, now time.Time) bool {
+	state := ks.states[kid]
+	return state.status.State == StateActive && (state.exp.IsZero() || !now.After(state.exp))
+}
+
+// lockMemory moves the key private material to locked memory when enabled
+func (ks *inMemoryKeyStore) lockMemory(k key.Key) error {
+	if !ks.opts.lockMemory {
+		return nil
+	}
+
+	err := key.LockMemory(k)
+	if err != nil && err != key.ErrInvalidOperationNotSupported {
+		return fmt.Errorf("keystore: Unable to lock key memory %v", err)
+	}
+
 	return nil
 }
@@ -1,7 +1,11 @@
 package keystore
 
 import (
+	"context"
+	"crypto"
+	"encoding/base64"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -30,3 +34,237 @@ func TestInMemoryKeystore(t *testing.T) {
 	Expect(err).To(BeNil(), "Error should be nil on construction")
 	Expect(kl).ToNot(BeNil(), "Public Keys collection should not be nil")
 }
+
+func TestInMemoryKeystore_OnlyPublicKeysWithSymmetricKey(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.HS256)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	k, err := ks.Generate()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	Expect(ks.Add(k)).To(BeNil(), "Error should be nil on addition")
+
+	kl, err := ks.OnlyPublicKeys()
+	Expect(err).To(BeNil(), "Error should be nil on public keys retrieval")
+	Expect(len(kl)).To(Equal(0), "Symmetric keys should never be published")
+}
+
+func TestInMemoryKeystore_IDStrategy(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.ES256, WithIDStrategy(key.ThumbprintID(crypto.SHA256)))
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	k, err := ks.Generate()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+
+	tp, err := k.Thumbprint(crypto.SHA256)
+	Expect(err).To(BeNil(), "Error should be nil on thumbprint computation")
+	Expect(k.ID()).To(Equal(base64.RawURLEncoding.EncodeToString(tp)), "Key identifier should be the JWK thumbprint")
+
+	// A static identifier is assigned to every generated key
+	ks, err = NewInMemory(key.ES256, WithIDStrategy(key.StaticID("signing")))
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	k1, err := ks.Generate()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	Expect(ks.Add(k1)).To(BeNil(), "Error should be nil on addition")
+
+	_, err = ks.Generate()
+	Expect(err).To(Equal(ErrKeyIDCollision), "Known identifier should not be generated")
+
+	k2, err := key.ES256()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	Expect(key.AssignID(k2, key.StaticID("signing"))).To(BeNil(), "Error should be nil on identifier assignment")
+	Expect(ks.Add(k2)).To(Equal(ErrKeyAlreadyExists), "Known identifier should not be replaced")
+	Expect(ks.AddPending(k2)).To(Equal(ErrKeyAlreadyExists), "Known identifier should not be replaced")
+
+	got, err := ks.Get("signing")
+	Expect(err).To(BeNil(), "Error should be nil on retrieval")
+	expected, _ := k1.Thumbprint(crypto.SHA256)
+	Expect(got.Thumbprint(crypto.SHA256)).To(Equal(expected), "First key should be kept")
+
+	// Rotation could not generate several keys
+	policy := DefaultRotationPolicy()
+	policy.MinActive = 2
+	ks, err = NewInMemory(key.ES256, WithIDStrategy(key.StaticID("signing")), WithRotationPolicy(policy))
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+	err = ks.RotateKeys(context.Background())
+	Expect(err).ToNot(BeNil(), "Rotation should report the identifier collision")
+	Expect(err.Error()).To(ContainSubstring(ErrKeyIDCollision.Error()))
+}
+
+func TestInMemoryKeystore_PublicKeySet(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	k, err := ks.Generate()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	Expect(ks.Add(k)).To(BeNil(), "Error should be nil on addition")
+
+	set, err := ks.PublicKeySet()
+	Expect(err).To(BeNil(), "Error should be nil on set retrieval")
+
+	pub, ok := set.LookupKeyID(k.ID())
+	Expect(ok).To(BeTrue(), "Key should be in the set")
+	Expect(pub.HasPrivate()).To(BeFalse(), "Set should only contain public keys")
+}
+
+func TestInMemoryKeystore_Pick(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	_, err = ks.Pick()
+	Expect(err).To(Equal(ErrNoSigningKey), "Empty keystore should not pick a key")
+
+	enc, err := key.X25519()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	Expect(ks.Add(enc)).To(BeNil(), "Error should be nil on addition")
+
+	verifyOnly, err := ks.Generate()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	verifyOnly.Metadata().KeyOperations = []string{key.OperationVerify}
+	Expect(ks.Add(verifyOnly)).To(BeNil(), "Error should be nil on addition")
+
+	_, err = ks.Pick()
+	Expect(err).To(Equal(ErrNoSigningKey), "Keys that could not sign should be skipped")
+
+	signer, err := ks.Generate()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	Expect(ks.Add(signer)).To(BeNil(), "Error should be nil on addition")
+
+	for i := 0; i < 3; i++ {
+		k, err := ks.Pick()
+		Expect(err).To(BeNil(), "Error should be nil on pick")
+		Expect(k.ID()).To(Equal(signer.ID()), "Only the signing key should be picked")
+	}
+
+	Expect(ks.Remove(signer.ID())).To(BeNil(), "Error should be nil on removal")
+	_, err = ks.Pick()
+	Expect(err).To(Equal(ErrNoSigningKey), "Removed key should not be picked")
+}
+
+func TestInMemoryKeystore_RemoveWipesKey(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519, WithMemoryLock())
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	k, _ := ks.Generate()
+	Expect(ks.Add(k)).To(BeNil(), "Error should be nil on add")
+
+	got, err := ks.Get(k.ID())
+	Expect(err).To(BeNil(), "Error should be nil on retrieval")
+	picked, err := ks.Pick()
+	Expect(err).To(BeNil(), "Error should be nil on pick")
+
+	Expect(ks.Remove(k.ID())).To(BeNil(), "Error should be nil on remove")
+	Expect(k.HasPrivate()).To(BeFalse(), "Removed key should be wiped")
+
+	// Retrieved keys only hold the public part
+	Expect(got.ID()).To(Equal(k.ID()))
+	Expect(got.HasPrivate()).To(BeFalse(), "Retrieved key should not expose the private part")
+
+	// Picked keys are copies, left untouched by the removal
+	Expect(picked.ID()).To(Equal(k.ID()))
+	sig, err := picked.Sign([]byte("payload"))
+	Expect(err).To(BeNil(), "Copy should still sign")
+	valid, err := got.Verify([]byte("payload"), sig)
+	Expect(err).To(BeNil())
+	Expect(valid).To(BeTrue())
+
+	key.Destroy(picked)
+	Expect(picked.HasPrivate()).To(BeFalse(), "Picked copy should be wiped by its owner")
+
+	_, err = ks.Get(k.ID())
+	Expect(err).To(Equal(ErrKeyNotFound))
+}
+
+func TestInMemoryKeystore_RotateKeys(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	now := time.Now()
+	ks.(*inMemoryKeyStore).now = func() time.Time { return now }
+
+	expiring, _ := ks.Generate()
+	Expect(ks.AddWithExpiration(expiring, time.Hour)).To(BeNil(), "Error should be nil on addition")
+	permanent, _ := ks.Generate()
+	Expect(ks.Add(permanent)).To(BeNil(), "Error should be nil on addition")
+
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+	keys, _ := ks.All()
+	Expect(len(keys)).To(Equal(2), "Keys should not be retired before expiration")
+
+	// Expired keys are kept for verification but not picked anymore
+	now = now.Add(time.Hour + time.Minute)
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+	for i := 0; i < 3; i++ {
+		k, err := ks.Pick()
+		Expect(err).To(BeNil(), "Error should be nil on pick")
+		Expect(k.ID()).To(Equal(permanent.ID()), "Expired key should not be picked")
+	}
+	_, err = ks.Get(expiring.ID())
+	Expect(err).To(BeNil(), "Expired key should be kept during grace period")
+
+	// Purged after the grace period
+	now = now.Add(DefaultRotationPolicy().GracePeriod)
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+	_, err = ks.Get(expiring.ID())
+	Expect(err).To(Equal(ErrKeyNotFound), "Expired key should be removed after grace period")
+	Expect(expiring.HasPrivate()).To(BeFalse(), "Removed key should be wiped")
+
+	_, err = ks.Get(permanent.ID())
+	Expect(err).To(BeNil(), "Key without expiration should be kept")
+}
+
+func TestInMemoryKeystore_MaxKeyAge(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519, WithMaxKeyAge(24*time.Hour))
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	now := time.Now()
+	ks.(*inMemoryKeyStore).now = func() time.Time { return now }
+
+	k, _ := ks.Generate()
+	Expect(ks.Add(k)).To(BeNil(), "Error should be nil on addition")
+
+	now = k.CreatedAt().Add(25 * time.Hour)
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+	picked, err := ks.Pick()
+	Expect(err).To(BeNil(), "Replacement key should be generated")
+	Expect(picked.ID()).ToNot(Equal(k.ID()), "Key older than the maximal age should not be picked")
+
+	now = now.Add(DefaultRotationPolicy().GracePeriod)
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+	_, err = ks.Get(k.ID())
+	Expect(err).To(Equal(ErrKeyNotFound), "Old key should be removed after grace period")
+}
+
+func TestInMemoryKeystore_PickNewest(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519, WithSelector(NewestActive()))
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	older, err := key.FromString([]byte(`{"kty":"OKP","crv":"Ed25519","iat":1500000000,"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	Expect(err).To(BeNil(), "Error should be nil on decoding")
+	Expect(ks.Add(older)).To(BeNil(), "Error should be nil on addition")
+
+	newest, _ := ks.Generate()
+	Expect(ks.Add(newest)).To(BeNil(), "Error should be nil on addition")
+
+	for i := 0; i < 3; i++ {
+		k, err := ks.Pick()
+		Expect(err).To(BeNil(), "Error should be nil on pick")
+		Expect(k.ID()).To(Equal(newest.ID()), "Newest key should always be picked")
+	}
+}
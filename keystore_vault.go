@@ -5,23 +5,43 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/Sirupsen/logrus"
-	"github.com/davecgh/go-spew/spew"
 	vault "github.com/hashicorp/vault/api"
 
 	"go.zenithar.org/keystore/key"
 )
 
 type vaultKeyStore struct {
+	sync.Mutex
+
 	prefix    string
 	generator KeyGenerator
+	opts      *options
 	client    *vault.Client
+
+	// Pick candidates cache
+	candidates []vaultCandidate
+	loadedAt   time.Time
+	// generation is incremented on each invalidation, a load started before
+	// is discarded as it could miss the change
+	generation uint64
+	// loading is closed when the load in progress completes
+	loading chan struct{}
+}
+
+// vaultCandidate is a signing key with its vault expiration
+type vaultCandidate struct {
+	key key.Key
+	exp time.Time
 }
 
-// NewVault returns a vault based keystore
-func NewVault(generator KeyGenerator, prefix string) (KeyStore, error) {
+// NewVault returns a vault based keystore. The returned keys are decoded for
+// the caller, which owns them and should wipe the picked ones with
+// key.Destroy once done.
+func NewVault(generator KeyGenerator, prefix string, opts ...Option) (KeyStore, error) {
 
 	// Initialize Vault Client
 	config := vault.DefaultConfig()
@@ -44,9 +64,15 @@ func NewVault(generator KeyGenerator, prefix string) (KeyStore, error) {
 	}
 	c.SetToken(token)
 
+	o := applyOptions(opts)
+	if err := o.rotation.validate(); err != nil {
+		return nil, err
+	}
+
 	// Return keystore instance
 	return &vaultKeyStore{
 		generator: generator,
+		opts:      o,
 		client:    c,
 		prefix:    prefix,
 	}, nil
@@ -59,143 +85,283 @@ func (ks *vaultKeyStore) Generate() (key.Key, error) {
 		return nil, fmt.Errorf("keystore: Key generation error %v", err)
 	}
 
+	if err := key.AssignID(k, ks.opts.idStrategy); err != nil {
+		return nil, fmt.Errorf("keystore: Key identifier assignment error %v", err)
+	}
+
+	// The key could never be added
+	if _, err := ks.getKeySecret(k.ID()); err == nil {
+		return nil, ErrKeyIDCollision
+	}
+
 	return k, nil
 }
 
 func (ks *vaultKeyStore) All() ([]key.Key, error) {
 	var result []key.Key
 
-	secret, err := ks.client.Logical().List(ks.getSecretPath("jwk"))
+	err := ks.each(func(k key.Key, _ map[string]interface{}, _ *Status) {
+		result = append(result, k)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// No secret
-	if secret == nil || secret.Data == nil {
-		return result, nil
-	}
-
-	// No keys attribute
-	if _, ok := secret.Data["keys"]; !ok {
-		return result, nil
-	}
-
-	if keys, ok := secret.Data["keys"].([]interface{}); ok {
-		for _, kid := range keys {
-			k, err := ks.Get(kid.(string))
-			if err != nil {
-				logrus.WithError(err).WithField("kid", kid).Warn("Unable to decode key")
-				continue
-			}
-			result = append(result, k)
-		}
-	}
-
 	return result, nil
 }
 
 func (ks *vaultKeyStore) OnlyPublicKeys() ([]key.Key, error) {
 	var result []key.Key
 
-	keys, err := ks.All()
+	err := ks.each(func(k key.Key, _ map[string]interface{}, status *Status) {
+		// Skip revoked keys
+		if !status.State.Verifiable() {
+			return
+		}
+		// Skip keys without public part (symmetric keys)
+		if pub := k.Public(); pub.HasPublic() {
+			result = append(result, pub)
+		}
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	for _, i := range keys {
-		result = append(result, i.Public())
+	return result, nil
+}
+
+func (ks *vaultKeyStore) PublicKeySet() (*key.Set, error) {
+	keys, err := ks.OnlyPublicKeys()
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return key.NewSet(keys...), nil
 }
 
 func (ks *vaultKeyStore) Get(id string) (key.Key, error) {
-	var res key.Key
+	k, secret, err := ks.getEntry(id)
+	if err != nil {
+		return nil, err
+	}
 
-	secret, err := ks.getSecret(fmt.Sprintf("jwk/%s", id))
+	status, err := statusFromSecret(secret)
 	if err != nil {
-		return nil, fmt.Errorf("vault: Failed to retrieve secret")
+		return nil, fmt.Errorf("vault: Failed to decode key state: %v", err)
 	}
 
-	if value, ok := secret["value"].(string); ok {
-		// Deserialize key
-		k, err := key.FromString([]byte(value))
+	// Retired keys still verify signatures
+	if !status.State.Verifiable() {
+		return nil, ErrKeyRevoked
+	}
+
+	return k, nil
+}
+
+func (ks *vaultKeyStore) Pick() (key.Key, error) {
+	return ks.PickContext(context.Background())
+}
+
+func (ks *vaultKeyStore) PickContext(ctx context.Context) (key.Key, error) {
+	ks.Lock()
+	defer ks.Unlock()
+
+	// Reload candidates when the cache is stale
+	for ks.candidates == nil || time.Since(ks.loadedAt) >= ks.opts.pickTTL {
+		loaded, err := ks.reloadCandidates(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("vault: Failed to decode secret")
+			return nil, err
+		}
+		if loaded {
+			break
 		}
+	}
 
-		res = k
+	// Cached candidates could have expired since loading
+	now := time.Now().UTC()
+	var keys []key.Key
+	for _, c := range ks.candidates {
+		if c.exp.IsZero() || !now.After(c.exp) {
+			keys = append(keys, c.key)
+		}
 	}
 
-	return res, nil
-}
+	if len(keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
 
-func (ks *vaultKeyStore) Pick() (key.Key, error) {
-	return nil, ErrNotImplemented
+	// Cached keys are wiped when dropped, hand out a copy owned by the caller
+	k, err := ks.opts.selector.Select(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	return cloneKey(k)
 }
 
 func (ks *vaultKeyStore) Add(k key.Key) error {
-	// Marshal to json
-	jwk, err := json.Marshal(k)
+	return ks.add(k, StateActive, 0)
+}
+
+func (ks *vaultKeyStore) AddWithExpiration(k key.Key, exp time.Duration) error {
+	return ks.add(k, StateActive, exp)
+}
+
+func (ks *vaultKeyStore) AddPending(k key.Key) error {
+	return ks.add(k, StatePending, 0)
+}
+
+func (ks *vaultKeyStore) Remove(id string) error {
+	defer ks.invalidateCandidates()
+	return ks.removeSecret(fmt.Sprintf("jwk/%s", id))
+}
+
+func (ks *vaultKeyStore) Status(id string) (*Status, error) {
+	secret, err := ks.getKeySecret(id)
 	if err != nil {
-		return fmt.Errorf("vault: Unable to serialize key as JSON : %T:%v", err, err)
+		return nil, ErrKeyNotFound
 	}
 
-	// Check if key already exists
-	k2, _ := ks.Get(k.ID())
-	if k2 != nil {
-		return fmt.Errorf("vault: Unable to insert key, KID is already known")
+	return statusFromSecret(secret)
+}
+
+func (ks *vaultKeyStore) Transition(id string, to State) error {
+	defer ks.invalidateCandidates()
+
+	secret, err := ks.getKeySecret(id)
+	if err != nil {
+		return ErrKeyNotFound
 	}
 
-	// Store key in vault
-	err = ks.writeSecret(fmt.Sprintf("jwk/%s", k.ID()), map[string]interface{}{
-		"value": string(jwk),
-		"iat":   time.Now().UTC().Unix(),
+	return ks.transition(id, secret, to)
+}
+
+func (ks *vaultKeyStore) ByState(state State) ([]key.Key, error) {
+	var result []key.Key
+
+	err := ks.each(func(k key.Key, _ map[string]interface{}, status *Status) {
+		if status.State == state {
+			result = append(result, k)
+		}
 	})
 	if err != nil {
-		return fmt.Errorf("vault: Unable to add a key to the vault: %T:%v", err, err)
+		return nil, err
 	}
 
-	return nil
+	return result, nil
 }
 
-func (ks *vaultKeyStore) AddWithExpiration(k key.Key, exp time.Duration) error {
+func (ks *vaultKeyStore) RotateKeys(ctx context.Context) error {
+	defer ks.invalidateCandidates()
+	return ks.rotateJob(ctx)
+}
+
+// -----------------------------------------------------------------------------
+
+// add stores the key in the given state, exp is ignored when not positive
+func (ks *vaultKeyStore) add(k key.Key, state State, exp time.Duration) error {
 	// Marshal to json
 	jwk, err := json.Marshal(k)
 	if err != nil {
 		return fmt.Errorf("vault: Unable to serialize key as JSON : %T:%v", err, err)
 	}
 
-	// Check if key already exists
-	k2, _ := ks.Get(k.ID())
-	if k2 != nil {
+	// Check if key already exists, whatever its state
+	if _, _, err := ks.getEntry(k.ID()); err == nil {
 		return fmt.Errorf("vault: Unable to insert key, KID is already known")
 	}
 
-	// Store key in vault
-	err = ks.writeSecret(fmt.Sprintf("jwk/%s", k.ID()), map[string]interface{}{
+	now := time.Now().UTC()
+	secret := map[string]interface{}{
 		"value": string(jwk),
-		"iat":   time.Now().UTC().Unix(),
-		"exp":   time.Now().UTC().Add(exp).Unix(),
-	})
+		"iat":   issuedAt(k).Unix(),
+	}
+	if exp > 0 {
+		secret["exp"] = now.Add(exp).Unix()
+	}
+	statusToSecret(newStatus(state, now), secret)
+
+	// Store key in vault
+	err = ks.writeSecret(fmt.Sprintf("jwk/%s", k.ID()), secret)
 	if err != nil {
 		return fmt.Errorf("vault: Unable to add a key to the vault: %T:%v", err, err)
 	}
 
+	ks.invalidateCandidates()
 	return nil
 }
 
-func (ks *vaultKeyStore) Remove(id string) error {
-	return ks.removeSecret(fmt.Sprintf("jwk/%s", id))
+// transition updates the state of the key stored in secret, destroyed keys
+// are removed from the vault
+func (ks *vaultKeyStore) transition(id string, secret map[string]interface{}, to State) error {
+	status, err := statusFromSecret(secret)
+	if err != nil {
+		return fmt.Errorf("vault: Failed to decode key state: %v", err)
+	}
+	if err := status.transition(to, time.Now()); err != nil {
+		return err
+	}
+
+	if to == StateDestroyed {
+		return ks.Remove(id)
+	}
+
+	statusToSecret(status, secret)
+	return ks.writeSecret(fmt.Sprintf("jwk/%s", id), secret)
 }
 
-func (ks *vaultKeyStore) RotateKeys(ctx context.Context) error {
-	return ks.rotateJob()
+// getEntry retrieves a key and its secret, whatever the key state
+func (ks *vaultKeyStore) getEntry(id string) (key.Key, map[string]interface{}, error) {
+	secret, err := ks.getKeySecret(id)
+	if err != nil {
+		return nil, nil, ErrKeyNotFound
+	}
+
+	value, ok := secret["value"].(string)
+	if !ok {
+		return nil, nil, fmt.Errorf("vault: Failed to decode secret")
+	}
+
+	// Deserialize key
+	k, err := key.FromString([]byte(value))
+	if err != nil {
+		return nil, nil, fmt.Errorf("vault: Failed to decode secret")
+	}
+
+	return k, secret, nil
 }
 
-// -----------------------------------------------------------------------------
+// each calls fn with all keys of the vault along with their secret and state,
+// undecodable keys are skipped
+func (ks *vaultKeyStore) each(fn func(key.Key, map[string]interface{}, *Status)) error {
+	secret, err := ks.client.Logical().List(ks.getSecretPath("jwk"))
+	if err != nil {
+		return err
+	}
+
+	// No secret
+	if secret == nil || secret.Data == nil {
+		return nil
+	}
+
+	keys, _ := secret.Data["keys"].([]interface{})
+	for _, kid := range keys {
+		k, data, err := ks.getEntry(fmt.Sprintf("%v", kid))
+		if err != nil {
+			logrus.WithError(err).WithField("kid", kid).Warn("Unable to decode key")
+			continue
+		}
+		status, err := statusFromSecret(data)
+		if err != nil {
+			logrus.WithError(err).WithField("kid", kid).Warn("Unable to decode key state")
+			continue
+		}
+		fn(k, data, status)
+	}
+
+	return nil
+}
 
-func (ks *vaultKeyStore) rotateJob() error {
+func (ks *vaultKeyStore) rotateJob(ctx context.Context) error {
 	now := time.Now().UTC()
 
 	// Retrieve last rotation date
@@ -218,68 +384,184 @@ func (ks *vaultKeyStore) rotateJob() error {
 
 	// Update rotation date
 	err := ks.writeSecret("next_rotation", map[string]interface{}{
-		"value": now.Add(5 * time.Minute).UTC().Unix(),
+		"value": now.Add(ks.opts.rotation.CheckInterval).UTC().Unix(),
 	})
 	if err != nil {
 		return fmt.Errorf("vault: Unable to rotate keys, unable to update rotation date: %T:%v", err, err)
 	}
 
 	// List all keys
-	keys, err := ks.All()
+	var entries []rotationEntry
+	err = ks.each(func(k key.Key, secret map[string]interface{}, status *Status) {
+		exp, err := secretExpiration(secret)
+		if err != nil {
+			logrus.WithError(err).WithField("kid", k.ID()).Warn("[VAULT] Unable to decode key expiration, skipping ...")
+			return
+		}
+		if err := ks.persistLegacyStatus(k.ID(), secret, status); err != nil {
+			logrus.WithError(err).WithField("kid", k.ID()).Warn("[VAULT] Unable to persist key state, skipping ...")
+			return
+		}
+		entries = append(entries, rotationEntry{key: k, status: status, exp: exp})
+	})
 	if err != nil {
 		return fmt.Errorf("vault: Unable to rotate keys, unable to retrieve all keys: %T:%v", err, err)
 	}
 
-	// Ignore if no key available
-	if len(keys) == 0 {
-		logrus.Debug("[VAULT] No key to rotate, skipping ...")
-		return nil
+	return applyRotation(ctx, ks, ks.opts.rotation.plan(entries, now))
+}
+
+// loadCandidates retrieves the keys of the vault able to sign, skipping the
+// keys marked as unusable or expired
+func (ks *vaultKeyStore) loadCandidates() ([]vaultCandidate, error) {
+	result := []vaultCandidate{}
+
+	secret, err := ks.client.Logical().List(ks.getSecretPath("jwk"))
+	if err != nil {
+		return nil, fmt.Errorf("vault: Unable to list keys: %T:%v", err, err)
 	}
 
-	// For each key
-	for _, k := range keys {
-		secret, err := ks.getSecret(fmt.Sprintf("jwk/%s", k.ID()))
+	// No secret
+	if secret == nil || secret.Data == nil {
+		return result, nil
+	}
+
+	keys, _ := secret.Data["keys"].([]interface{})
+	now := time.Now().UTC()
+	for _, kid := range keys {
+		data, err := ks.getKeySecret(fmt.Sprintf("%v", kid))
 		if err != nil {
-			logrus.WithError(err).WithField("kid", k.ID()).Warn("[VAULT] Unable to retrieve key from vault, skipping ...")
+			logrus.WithError(err).WithField("kid", kid).Warn("[VAULT] Unable to retrieve key from vault, skipping ...")
 			continue
 		}
 
-		// Check expiration
-		if expRaw, ok := secret["exp"]; ok {
-			// Exp is a float64
-			spew.Dump(expRaw)
-			if expiration, ok := expRaw.(json.Number); ok {
-				v, _ := expiration.Int64()
-
-				// Calculate dates
-				expirationDate := time.Unix(v, 0)
-				deletionDate := expirationDate.Add(2 * time.Hour)
-
-				// Check expiration time
-				if now.After(expirationDate) {
-					// Mark as unuseable
-					secret["usable"] = false
-					err = ks.writeSecret(fmt.Sprintf("jwk/%s", k.ID()), secret)
-					if err != nil {
-						logrus.WithError(err).WithField("kid", k.ID()).Warn("[VAULT] Unable to save key in vault, skipping ...")
-						continue
-					}
-				}
-				if now.After(deletionDate) {
-					// Delete key after grace period
-					err = ks.Remove(k.ID())
-					if err != nil {
-						logrus.WithError(err).WithField("kid", k.ID()).Warn("[VAULT] Unable to remove key from vault, skipping ...")
-						continue
-					}
-				}
-			} else {
-				logrus.Error("vault: Expiration key type error.")
-			}
+		c, ok, err := parseCandidate(data, now)
+		if err != nil {
+			logrus.WithError(err).WithField("kid", kid).Warn("[VAULT] Unable to decode key, skipping ...")
+			continue
+		}
+		if ok {
+			result = append(result, c)
+		} else if c.key != nil {
+			key.Destroy(c.key)
 		}
 	}
 
-	return nil
+	return result, nil
+}
+
+// reloadCandidates loads the Pick candidates from the vault, or waits for the
+// load in progress and returns false. The lock is released during the vault
+// calls so that they do not block the other operations, caller must hold it.
+func (ks *vaultKeyStore) reloadCandidates(ctx context.Context) (bool, error) {
+	if wait := ks.loading; wait != nil {
+		ks.Unlock()
+		defer ks.Lock()
+
+		select {
+		case <-wait:
+			return false, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
+
+	done := make(chan struct{})
+	ks.loading = done
+	generation := ks.generation
+	ks.Unlock()
+
+	loadedAt := time.Now().UTC()
+	candidates, err := ks.loadCandidates()
+
+	ks.Lock()
+	ks.loading = nil
+	close(done)
+
+	if err != nil {
+		return false, err
+	}
+	if generation != ks.generation {
+		// Invalidated while loading
+		for _, c := range candidates {
+			key.Destroy(c.key)
+		}
+		return false, nil
+	}
+
+	ks.dropCandidates()
+	ks.candidates, ks.loadedAt = candidates, loadedAt
+	return true, nil
+}
+
+// invalidateCandidates forces the reload of the Pick candidates
+func (ks *vaultKeyStore) invalidateCandidates() {
+	ks.Lock()
+	ks.generation++
+	ks.dropCandidates()
+	ks.Unlock()
+}
+
+// dropCandidates wipes the cached candidates, caller must hold the lock
+func (ks *vaultKeyStore) dropCandidates() {
+	for _, c := range ks.candidates {
+		key.Destroy(c.key)
+	}
+	ks.candidates = nil
+}
+
+// parseCandidate decodes a key secret, and returns false when the key could
+// not be used for signing at the given date
+func parseCandidate(secret map[string]interface{}, now time.Time) (vaultCandidate, bool, error) {
+	var c vaultCandidate
+
+	status, err := statusFromSecret(secret)
+	if err != nil {
+		return c, false, err
+	}
+	if status.State != StateActive {
+		return c, false, nil
+	}
+
+	c.exp, err = secretExpiration(secret)
+	if err != nil {
+		return c, false, err
+	}
+	if !c.exp.IsZero() && now.After(c.exp) {
+		return c, false, nil
+	}
+
+	value, ok := secret["value"].(string)
+	if !ok {
+		return c, false, fmt.Errorf("vault: Key value type error")
+	}
+	k, err := key.FromString([]byte(value))
+	if err != nil {
+		return c, false, fmt.Errorf("vault: Failed to decode secret")
+	}
+	c.key = k
+
+	return c, key.CanSign(k), nil
+}
+
+// secretExpiration returns the expiration date of a key secret, zero when the
+// key has none
+func secretExpiration(secret map[string]interface{}) (time.Time, error) {
+	expRaw, ok := secret["exp"]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	expiration, ok := expRaw.(json.Number)
+	if !ok {
+		return time.Time{}, fmt.Errorf("vault: Expiration key type error")
+	}
+	v, err := expiration.Int64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("vault: Unable to decode expiration: %v", err)
+	}
+
+	return time.Unix(v, 0).UTC(), nil
 }
 
 func (ks *vaultKeyStore) getSecret(path string) (map[string]interface{}, error) {
@@ -290,6 +572,27 @@ func (ks *vaultKeyStore) getSecret(path string) (map[string]interface{}, error)
 	return secret.Data, nil
 }
 
+// getKeySecret retrieves the secret of a key, without persisting the status
+// of a secret written by previous versions so that read-only policies still
+// read the keystore
+func (ks *vaultKeyStore) getKeySecret(id string) (map[string]interface{}, error) {
+	return ks.getSecret(fmt.Sprintf("jwk/%s", id))
+}
+
+// persistLegacyStatus writes the status of a secret written by previous
+// versions, so that its dates no longer move on each read
+func (ks *vaultKeyStore) persistLegacyStatus(id string, secret map[string]interface{}, status *Status) error {
+	if _, ok := secret["state"]; ok {
+		return nil
+	}
+
+	statusToSecret(status, secret)
+	if err := ks.writeSecret(fmt.Sprintf("jwk/%s", id), secret); err != nil {
+		return fmt.Errorf("vault: Unable to persist key state: %T:%v", err, err)
+	}
+	return nil
+}
+
 func (ks *vaultKeyStore) getSecretPath(path string) string {
 	return fmt.Sprintf("secret/%s/%s", ks.prefix, path)
 }
@@ -2,10 +2,18 @@ package keystore
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	vault "github.com/hashicorp/vault/api"
 	. "github.com/onsi/gomega"
 
 	"go.zenithar.org/keystore/key"
@@ -41,3 +49,213 @@ func TestVaultKeystore(t *testing.T) {
 	err = ks.Remove(k.ID())
 	Expect(err).To(BeNil(), "Error should be nil on construction")
 }
+
+func TestVaultKeystore_ParseCandidate(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now().UTC()
+
+	k, err := key.Ed25519()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	jwk, err := json.Marshal(k)
+	Expect(err).To(BeNil(), "Error should be nil on serialization")
+
+	c, ok, err := parseCandidate(map[string]interface{}{
+		"value": string(jwk),
+		"exp":   json.Number(fmt.Sprintf("%d", now.Add(time.Hour).Unix())),
+	}, now)
+	Expect(err).To(BeNil(), "Error should be nil on parsing")
+	Expect(ok).To(BeTrue(), "Valid key should be a candidate")
+	Expect(c.key.ID()).To(Equal(k.ID()), "Candidate should hold the key")
+	Expect(c.exp.Unix()).To(Equal(now.Add(time.Hour).Unix()), "Candidate should hold the expiration")
+
+	_, ok, err = parseCandidate(map[string]interface{}{
+		"value":  string(jwk),
+		"usable": false,
+	}, now)
+	Expect(err).To(BeNil(), "Error should be nil on parsing")
+	Expect(ok).To(BeFalse(), "Unusable key should not be a candidate")
+
+	_, ok, err = parseCandidate(map[string]interface{}{
+		"value": string(jwk),
+		"exp":   json.Number(fmt.Sprintf("%d", now.Add(-time.Minute).Unix())),
+	}, now)
+	Expect(err).To(BeNil(), "Error should be nil on parsing")
+	Expect(ok).To(BeFalse(), "Expired key should not be a candidate")
+
+	pub, _ := json.Marshal(k.Public())
+	_, ok, err = parseCandidate(map[string]interface{}{
+		"value": string(pub),
+	}, now)
+	Expect(err).To(BeNil(), "Error should be nil on parsing")
+	Expect(ok).To(BeFalse(), "Public key should not be a candidate")
+
+	_, _, err = parseCandidate(map[string]interface{}{
+		"value": string(jwk),
+		"exp":   "tomorrow",
+	}, now)
+	Expect(err).ToNot(BeNil(), "Invalid expiration should raise an error")
+}
+
+func TestVaultKeystore_CandidatesWiped(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, err := key.Ed25519()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+
+	ks := &vaultKeyStore{
+		opts:       applyOptions(nil),
+		candidates: []vaultCandidate{{key: k}},
+		loadedAt:   time.Now().UTC(),
+	}
+
+	picked, err := ks.Pick()
+	Expect(err).To(BeNil(), "Error should be nil on pick")
+	Expect(picked.ID()).To(Equal(k.ID()), "Cached candidate should be picked")
+
+	// Removals and state changes drop the cache
+	ks.invalidateCandidates()
+	Expect(k.HasPrivate()).To(BeFalse(), "Cached key should be wiped")
+	Expect(picked.HasPrivate()).To(BeTrue(), "Picked key should be left untouched")
+}
+
+func TestVaultKeystore_ReadOnlyLegacyKey(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, err := key.Ed25519()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	value, _ := json.Marshal(k)
+
+	// Secret written by previous versions, without state
+	fake := newFakeVault(map[string]map[string]interface{}{
+		k.ID(): {"value": string(value), "iat": k.CreatedAt().Unix(), "usable": true},
+	})
+	defer fake.Close()
+	ks := fake.keystore()
+
+	got, err := ks.Get(k.ID())
+	Expect(err).To(BeNil(), "Legacy key should be read without write permission")
+	Expect(got.ID()).To(Equal(k.ID()))
+
+	status, err := ks.Status(k.ID())
+	Expect(err).To(BeNil(), "Error should be nil on status retrieval")
+	Expect(status.State).To(Equal(StateActive), "Legacy usable key should be active")
+
+	keys, err := ks.OnlyPublicKeys()
+	Expect(err).To(BeNil(), "Error should be nil on public keys retrieval")
+	Expect(keys).To(HaveLen(1))
+
+	picked, err := ks.Pick()
+	Expect(err).To(BeNil(), "Error should be nil on pick")
+	Expect(picked.ID()).To(Equal(k.ID()))
+
+	Expect(atomic.LoadInt32(&fake.writes)).To(BeZero(), "Reads should never write to the vault")
+}
+
+func TestVaultKeystore_PickLoadsWithoutLock(t *testing.T) {
+	RegisterTestingT(t)
+
+	k, err := key.Ed25519()
+	Expect(err).To(BeNil(), "Error should be nil on generation")
+	value, _ := json.Marshal(k)
+
+	fake := newFakeVault(map[string]map[string]interface{}{
+		k.ID(): {"value": string(value), "state": string(StateActive)},
+	})
+	fake.block = make(chan struct{})
+	release := sync.OnceFunc(func() { close(fake.block) })
+	defer fake.Close()
+	defer release()
+	ks := fake.keystore()
+
+	errs := make(chan error, 4)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			picked, err := ks.Pick()
+			if err == nil {
+				key.Destroy(picked)
+			}
+			errs <- err
+		}()
+	}
+	Eventually(func() int32 { return atomic.LoadInt32(&fake.reads) }).Should(Equal(int32(1)), "Candidates should be loaded once")
+
+	// Invalidations do not wait for the load in progress, which is discarded
+	invalidated := make(chan struct{})
+	go func() {
+		ks.invalidateCandidates()
+		close(invalidated)
+	}()
+	Eventually(invalidated).Should(BeClosed(), "Invalidation should not wait for the vault")
+
+	release()
+	for i := 0; i < cap(errs); i++ {
+		Expect(<-errs).To(BeNil(), "Error should be nil on pick")
+	}
+	Expect(atomic.LoadInt32(&fake.reads)).To(Equal(int32(2)), "Invalidated candidates should be loaded again")
+}
+
+// fakeVault serves the key secrets of a read-only vault, and counts the
+// rejected writes
+type fakeVault struct {
+	*httptest.Server
+	secrets map[string]map[string]interface{}
+	writes  int32
+	reads   int32
+	// block holds the key reads until closed
+	block chan struct{}
+}
+
+func newFakeVault(secrets map[string]map[string]interface{}) *fakeVault {
+	f := &fakeVault{secrets: secrets}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serve))
+	return f
+}
+
+func (f *fakeVault) serve(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/v1/secret/test/jwk"
+
+	if r.Method != http.MethodGet && r.Method != "LIST" {
+		atomic.AddInt32(&f.writes, 1)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	var data map[string]interface{}
+	switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+	case path == prefix && (r.Method == "LIST" || r.URL.Query().Get("list") == "true"):
+		var keys []interface{}
+		for kid := range f.secrets {
+			keys = append(keys, kid)
+		}
+		data = map[string]interface{}{"keys": keys}
+	case strings.HasPrefix(path, prefix+"/"):
+		atomic.AddInt32(&f.reads, 1)
+		if f.block != nil {
+			<-f.block
+		}
+		data = f.secrets[strings.TrimPrefix(path, prefix+"/")]
+	}
+
+	if data == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// keystore returns a vault keystore using the fake vault
+func (f *fakeVault) keystore() *vaultKeyStore {
+	config := vault.DefaultConfig()
+	config.Address = f.URL
+	config.MaxRetries = 0
+	client, err := vault.NewClient(config)
+	Expect(err).To(BeNil(), "Error should be nil on client creation")
+	client.SetToken("test")
+
+	return &vaultKeyStore{
+		prefix: "test",
+		opts:   applyOptions(nil),
+		client: client,
+	}
+}
@@ -0,0 +1,179 @@
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// State is the lifecycle state of a key in a keystore
+type State string
+
+const (
+	// StatePending keys are published for verification ahead of their activation
+	StatePending State = "pending"
+	// StateActive keys are used for signing and verification
+	StateActive State = "active"
+	// StateRetired keys are not used for signing anymore, but still verify
+	// the signatures they produced
+	StateRetired State = "retired"
+	// StateRevoked keys must not be trusted anymore
+	StateRevoked State = "revoked"
+	// StateDestroyed keys have been wiped and removed from the keystore
+	StateDestroyed State = "destroyed"
+)
+
+// transitions lists the legal transitions from each state
+var transitions = map[State][]State{
+	StatePending: {StateActive, StateRevoked, StateDestroyed},
+	StateActive:  {StateRetired, StateRevoked},
+	StateRetired: {StateRevoked, StateDestroyed},
+	StateRevoked: {StateDestroyed},
+}
+
+// CanTransition returns true if a key could go from one state to another
+func CanTransition(from, to State) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifiable returns true if the keys in this state could verify signatures
+func (s State) Verifiable() bool {
+	return s == StatePending || s == StateActive || s == StateRetired
+}
+
+// Status holds the lifecycle state of a key, along with the date each state
+// was entered
+type Status struct {
+	State State
+	Dates map[State]time.Time
+}
+
+func newStatus(state State, at time.Time) *Status {
+	return &Status{
+		State: state,
+		Dates: map[State]time.Time{state: at.UTC()},
+	}
+}
+
+// Since returns the date the given state was entered, false if the key never
+// was in this state
+func (s *Status) Since(state State) (time.Time, bool) {
+	at, ok := s.Dates[state]
+	return at, ok
+}
+
+// transition moves to the given state when the transition is legal
+func (s *Status) transition(to State, at time.Time) error {
+	if !CanTransition(s.State, to) {
+		return &TransitionError{From: s.State, To: to}
+	}
+
+	s.State = to
+	s.Dates[to] = at.UTC()
+
+	return nil
+}
+
+// clone returns a deep copy of the status
+func (s *Status) clone() *Status {
+	c := &Status{
+		State: s.State,
+		Dates: make(map[State]time.Time, len(s.Dates)),
+	}
+	for state, at := range s.Dates {
+		c.Dates[state] = at
+	}
+	return c
+}
+
+// TransitionError is raised on an illegal lifecycle transition
+type TransitionError struct {
+	From State
+	To   State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("keystore: Illegal key state transition from %s to %s", e.From, e.To)
+}
+
+// -----------------------------------------------------------------------------
+
+// statusToSecret writes the status to a vault secret
+func statusToSecret(s *Status, secret map[string]interface{}) {
+	dates := make(map[string]interface{}, len(s.Dates))
+	for state, at := range s.Dates {
+		dates[string(state)] = at.Unix()
+	}
+
+	secret["state"] = string(s.State)
+	secret["state_dates"] = dates
+	// Keep the flag of previous versions in sync
+	secret["usable"] = s.State == StateActive
+}
+
+// statusFromSecret reads the status of a vault secret, secrets written by
+// previous versions are active unless marked as unusable
+func statusFromSecret(secret map[string]interface{}) (*Status, error) {
+	state, ok := secret["state"].(string)
+	if !ok {
+		return legacyStatus(secret)
+	}
+
+	s := &Status{
+		State: State(state),
+		Dates: map[State]time.Time{},
+	}
+	if _, ok := transitions[s.State]; !ok && s.State != StateDestroyed {
+		return nil, fmt.Errorf("keystore: Unknown key state %q", state)
+	}
+
+	dates, _ := secret["state_dates"].(map[string]interface{})
+	for name, raw := range dates {
+		v, ok := raw.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("keystore: Invalid key state date type %T", raw)
+		}
+		at, err := v.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("keystore: Unable to decode key state date %v", err)
+		}
+		s.Dates[State(name)] = time.Unix(at, 0).UTC()
+	}
+
+	return s, nil
+}
+
+// legacyStatus returns the status of a secret written by previous versions,
+// the key is active since its creation, or retired since its expiration when
+// marked as unusable. The date is now when unknown, until the status is
+// persisted by Transition or RotateKeys.
+func legacyStatus(secret map[string]interface{}) (*Status, error) {
+	// Secrets only store dates to the second
+	now := time.Now().Truncate(time.Second)
+
+	if usable, ok := secret["usable"].(bool); ok && !usable {
+		exp, err := secretExpiration(secret)
+		if err != nil {
+			return nil, err
+		}
+		if exp.IsZero() {
+			exp = now
+		}
+		return newStatus(StateRetired, exp), nil
+	}
+
+	at := now
+	if iat, ok := secret["iat"].(json.Number); ok {
+		v, err := iat.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("keystore: Unable to decode key creation date %v", err)
+		}
+		at = time.Unix(v, 0)
+	}
+	return newStatus(StateActive, at), nil
+}
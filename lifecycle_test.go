@@ -0,0 +1,190 @@
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"go.zenithar.org/keystore/key"
+)
+
+func TestLifecycle_Transitions(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(CanTransition(StatePending, StateActive)).To(BeTrue())
+	Expect(CanTransition(StateActive, StateRetired)).To(BeTrue())
+	Expect(CanTransition(StateRetired, StateRevoked)).To(BeTrue())
+	Expect(CanTransition(StateRevoked, StateDestroyed)).To(BeTrue())
+
+	Expect(CanTransition(StateActive, StatePending)).To(BeFalse())
+	Expect(CanTransition(StateRetired, StateActive)).To(BeFalse())
+	Expect(CanTransition(StateActive, StateDestroyed)).To(BeFalse())
+	Expect(CanTransition(StateDestroyed, StateActive)).To(BeFalse())
+
+	now := time.Now().UTC()
+	s := newStatus(StatePending, now)
+	Expect(s.transition(StateActive, now.Add(time.Hour))).To(BeNil())
+	Expect(s.transition(StatePending, now)).To(Equal(&TransitionError{From: StateActive, To: StatePending}))
+
+	at, ok := s.Since(StatePending)
+	Expect(ok).To(BeTrue())
+	Expect(at).To(Equal(now))
+	at, ok = s.Since(StateActive)
+	Expect(ok).To(BeTrue())
+	Expect(at).To(Equal(now.Add(time.Hour)))
+	_, ok = s.Since(StateRetired)
+	Expect(ok).To(BeFalse())
+}
+
+func TestLifecycle_Secret(t *testing.T) {
+	RegisterTestingT(t)
+
+	// Vault returns numbers as json.Number
+	decode := func(secret map[string]interface{}) map[string]interface{} {
+		raw, err := json.Marshal(secret)
+		Expect(err).To(BeNil())
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.UseNumber()
+		out := map[string]interface{}{}
+		Expect(d.Decode(&out)).To(BeNil())
+		return out
+	}
+
+	now := time.Unix(1500000000, 0).UTC()
+	s := newStatus(StateActive, now)
+	Expect(s.transition(StateRetired, now.Add(time.Hour))).To(BeNil())
+
+	secret := map[string]interface{}{}
+	statusToSecret(s, secret)
+	Expect(secret["usable"]).To(BeFalse())
+
+	restored, err := statusFromSecret(decode(secret))
+	Expect(err).To(BeNil())
+	Expect(restored).To(Equal(s))
+
+	// Secrets written by previous versions
+	legacy, err := statusFromSecret(decode(map[string]interface{}{"iat": now.Unix()}))
+	Expect(err).To(BeNil())
+	Expect(legacy).To(Equal(newStatus(StateActive, now)))
+
+	// Unusable keys are retired since their expiration, not their creation
+	exp := now.Add(24 * time.Hour)
+	legacy, err = statusFromSecret(decode(map[string]interface{}{"iat": now.Add(-time.Hour).Unix(), "exp": exp.Unix(), "usable": false}))
+	Expect(err).To(BeNil())
+	Expect(legacy).To(Equal(newStatus(StateRetired, exp)))
+
+	legacy, err = statusFromSecret(decode(map[string]interface{}{"iat": now.Add(-time.Hour).Unix(), "usable": false}))
+	Expect(err).To(BeNil())
+	Expect(legacy.State).To(Equal(StateRetired))
+	Expect(legacy.Dates[StateRetired]).To(BeTemporally("~", time.Now(), time.Second))
+
+	// Once persisted, the date does not move anymore
+	secret = map[string]interface{}{}
+	statusToSecret(legacy, secret)
+	restored, err = statusFromSecret(decode(secret))
+	Expect(err).To(BeNil())
+	Expect(restored).To(Equal(legacy))
+
+	_, err = statusFromSecret(map[string]interface{}{"state": "lost"})
+	Expect(err).ToNot(BeNil())
+}
+
+func TestInMemoryKeystore_Lifecycle(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	current, _ := ks.Generate()
+	Expect(ks.Add(current)).To(BeNil(), "Error should be nil on addition")
+
+	// Next key is published ahead of its activation
+	next, _ := ks.Generate()
+	Expect(ks.AddPending(next)).To(BeNil(), "Error should be nil on addition")
+
+	set, err := ks.PublicKeySet()
+	Expect(err).To(BeNil(), "Error should be nil on set retrieval")
+	_, ok := set.LookupKeyID(next.ID())
+	Expect(ok).To(BeTrue(), "Pending key should be published")
+
+	for i := 0; i < 3; i++ {
+		k, err := ks.Pick()
+		Expect(err).To(BeNil(), "Error should be nil on pick")
+		Expect(k.ID()).To(Equal(current.ID()), "Pending key should not be picked")
+	}
+
+	pending, err := ks.ByState(StatePending)
+	Expect(err).To(BeNil(), "Error should be nil on query")
+	Expect(pending).To(HaveLen(1), "Only one key should be pending")
+
+	// Rollover
+	Expect(ks.Transition(next.ID(), StateActive)).To(BeNil(), "Error should be nil on activation")
+	Expect(ks.Transition(current.ID(), StateRetired)).To(BeNil(), "Error should be nil on retirement")
+
+	k, err := ks.Pick()
+	Expect(err).To(BeNil(), "Error should be nil on pick")
+	Expect(k.ID()).To(Equal(next.ID()), "Retired key should not be picked")
+
+	k, err = ks.Get(current.ID())
+	Expect(err).To(BeNil(), "Retired key should still verify")
+	Expect(k.ID()).To(Equal(current.ID()))
+
+	status, err := ks.Status(current.ID())
+	Expect(err).To(BeNil(), "Error should be nil on status retrieval")
+	Expect(status.State).To(Equal(StateRetired))
+	_, ok = status.Since(StateRetired)
+	Expect(ok).To(BeTrue(), "Retirement date should be recorded")
+
+	Expect(ks.Transition(current.ID(), StateActive)).To(BeAssignableToTypeOf(&TransitionError{}), "Retired key should not be reactivated")
+
+	// Revocation
+	Expect(ks.Transition(current.ID(), StateRevoked)).To(BeNil(), "Error should be nil on revocation")
+	_, err = ks.Get(current.ID())
+	Expect(err).To(Equal(ErrKeyRevoked), "Revoked key should not verify")
+
+	set, _ = ks.PublicKeySet()
+	_, ok = set.LookupKeyID(current.ID())
+	Expect(ok).To(BeFalse(), "Revoked key should not be published")
+
+	// Adding the key again does not bypass the transitions
+	Expect(ks.Add(current)).To(Equal(ErrKeyAlreadyExists), "Revoked key should not be re-added")
+	Expect(ks.AddPending(current)).To(Equal(ErrKeyAlreadyExists), "Revoked key should not be re-added")
+	Expect(ks.AddWithExpiration(current, time.Hour)).To(Equal(ErrKeyAlreadyExists), "Revoked key should not be re-added")
+	status, err = ks.Status(current.ID())
+	Expect(err).To(BeNil(), "Error should be nil on status retrieval")
+	Expect(status.State).To(Equal(StateRevoked), "Revoked key should stay revoked")
+	_, err = ks.Get(current.ID())
+	Expect(err).To(Equal(ErrKeyRevoked), "Revoked key should not verify")
+
+	// Destruction
+	Expect(ks.Transition(current.ID(), StateDestroyed)).To(BeNil(), "Error should be nil on destruction")
+	Expect(current.HasPrivate()).To(BeFalse(), "Destroyed key should be wiped")
+	_, err = ks.Status(current.ID())
+	Expect(err).To(Equal(ErrKeyNotFound), "Destroyed key should be removed")
+
+	Expect(ks.Transition("unknown", StateActive)).To(Equal(ErrKeyNotFound))
+}
+
+func TestInMemoryKeystore_LifecycleRotation(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	now := time.Now()
+	ks.(*inMemoryKeyStore).now = func() time.Time { return now }
+
+	k, _ := ks.Generate()
+	Expect(ks.AddWithExpiration(k, time.Hour)).To(BeNil(), "Error should be nil on addition")
+
+	now = now.Add(2 * time.Hour)
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+
+	status, err := ks.Status(k.ID())
+	Expect(err).To(BeNil(), "Error should be nil on status retrieval")
+	Expect(status.State).To(Equal(StateRetired), "Expired key should be retired")
+}
@@ -0,0 +1,118 @@
+package keystore
+
+import (
+	"time"
+
+	"go.zenithar.org/keystore/key"
+)
+
+// Option defines a keystore optional parameter
+type Option func(*options)
+
+type options struct {
+	idStrategy key.IDStrategy
+	lockMemory bool
+	rotation   RotationPolicy
+	selector   Selector
+	pickTTL    time.Duration
+
+	// Rotation settings merged once all options are applied
+	policy      *RotationPolicy
+	maxKeyAge   time.Duration
+	gracePeriod time.Duration
+}
+
+func defaultOptions() *options {
+	return &options{
+		idStrategy: key.LibtrustID,
+		selector:   RoundRobin(),
+		pickTTL:    time.Minute,
+		rotation:   DefaultRotationPolicy(),
+	}
+}
+
+func applyOptions(opts []Option) *options {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Dedicated options complete the rotation policy, whatever their order
+	if o.policy != nil {
+		o.rotation = *o.policy
+	}
+	if o.rotation.KeyLifetime == 0 {
+		o.rotation.KeyLifetime = o.maxKeyAge
+	}
+	if o.gracePeriod > 0 && (o.policy == nil || o.rotation.GracePeriod == 0) {
+		o.rotation.GracePeriod = o.gracePeriod
+	}
+
+	return o
+}
+
+// WithIDStrategy sets the strategy used to assign identifiers to generated
+// keys, a keystore refuses to add a key with an already known identifier.
+// Generate fails with ErrKeyIDCollision when the strategy assigns a known
+// identifier, as key.StaticID does once its key has been added.
+func WithIDStrategy(strategy key.IDStrategy) Option {
+	return func(o *options) {
+		o.idStrategy = strategy
+	}
+}
+
+// WithMemoryLock moves the private material of the keys added to the keystore
+// to locked memory, for the key types supporting it (see key.LockMemory). The
+// copies returned by Pick are also locked, destroy them once done so that a
+// busy signer does not exhaust the locked memory limit (RLIMIT_MEMLOCK).
+func WithMemoryLock() Option {
+	return func(o *options) {
+		o.lockMemory = true
+	}
+}
+
+// WithMaxKeyAge retires the keys without explicit expiration once they are
+// older than the given age (see key.Key CreatedAt) during keys rotation, it
+// sets the KeyLifetime of the rotation policy when the policy has none
+func WithMaxKeyAge(age time.Duration) Option {
+	return func(o *options) {
+		o.maxKeyAge = age
+	}
+}
+
+// WithGracePeriod sets how long an expired key is kept for verification before
+// its removal during keys rotation, it sets the GracePeriod of the rotation
+// policy when the default policy is used or the policy has none
+func WithGracePeriod(period time.Duration) Option {
+	return func(o *options) {
+		o.gracePeriod = period
+	}
+}
+
+// WithRotationPolicy sets the policy applied by RotateKeys, its KeyLifetime
+// and GracePeriod take precedence over WithMaxKeyAge and WithGracePeriod
+func WithRotationPolicy(policy RotationPolicy) Option {
+	return func(o *options) {
+		o.policy = &policy
+	}
+}
+
+// WithSelector sets the strategy used by Pick to select a signing key,
+// RoundRobin by default. The selector is called by concurrent Picks while the
+// keystore is locked, it must be safe for concurrent use and must not call the
+// keystore.
+func WithSelector(selector Selector) Option {
+	return func(o *options) {
+		if selector != nil {
+			o.selector = selector
+		}
+	}
+}
+
+// WithPickCacheTTL sets how long the keystores backed by a remote storage keep
+// the candidate keys of Pick before reloading them
+func WithPickCacheTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.pickTTL = ttl
+	}
+}
@@ -0,0 +1,229 @@
+package keystore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.zenithar.org/keystore/key"
+)
+
+// RotationPolicy drives the key rotation done by RotateKeys
+type RotationPolicy struct {
+	// KeyLifetime is the time a key without explicit expiration stays active
+	// after its creation, keys never expire when zero
+	KeyLifetime time.Duration
+	// PrePublish is the lead time a replacement key is published as pending
+	// before the expiration of an active key, pending keys are activated once
+	// published for this time, disabled when zero
+	PrePublish time.Duration
+	// GracePeriod is the time a retired or revoked key is kept for
+	// verification before its destruction
+	GracePeriod time.Duration
+	// MinActive is the count of active keys maintained by generating
+	// replacement keys, no key is generated when zero
+	MinActive int
+	// MaxActive is the maximal count of active keys, the oldest ones are
+	// retired first, unlimited when zero
+	MaxActive int
+	// CheckInterval is the minimal time between two rotations, RotateKeys
+	// does nothing when called again within this interval
+	CheckInterval time.Duration
+}
+
+// DefaultRotationPolicy returns the policy used when none is configured: keys
+// never expire by age, expired keys are destroyed after 2 hours, and a key is
+// generated when none is active
+func DefaultRotationPolicy() RotationPolicy {
+	return RotationPolicy{
+		GracePeriod:   2 * time.Hour,
+		MinActive:     1,
+		CheckInterval: 5 * time.Minute,
+	}
+}
+
+// ErrInvalidRotationPolicy is raised when building a keystore with an
+// inconsistent rotation policy
+var ErrInvalidRotationPolicy = errors.New("keystore: Invalid rotation policy")
+
+func (p *RotationPolicy) validate() error {
+	if p.KeyLifetime < 0 || p.PrePublish < 0 || p.GracePeriod < 0 || p.CheckInterval < 0 {
+		return ErrInvalidRotationPolicy
+	}
+	if p.MinActive < 0 || p.MaxActive < 0 {
+		return ErrInvalidRotationPolicy
+	}
+	if p.MaxActive > 0 && p.MaxActive < p.MinActive {
+		return ErrInvalidRotationPolicy
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// rotationEntry is a key of the keystore as seen by the rotation
+type rotationEntry struct {
+	key    key.Key
+	status *Status
+	// exp is the explicit expiration date, zero when none
+	exp time.Time
+}
+
+// rotationPlan lists the changes to apply to the keystore
+type rotationPlan struct {
+	retire          []string
+	activate        []string
+	destroy         []string
+	generateActive  int
+	generatePending int
+}
+
+// expiration returns the explicit expiration date of the key, or the end of
+// its lifetime
+func (p *RotationPolicy) expiration(e rotationEntry) (time.Time, bool) {
+	if !e.exp.IsZero() {
+		return e.exp, true
+	}
+	return ageExpiration(e.key, p.KeyLifetime)
+}
+
+// plan computes the changes required by the policy at the given date
+func (p *RotationPolicy) plan(entries []rotationEntry, now time.Time) *rotationPlan {
+	var (
+		plan    = &rotationPlan{}
+		active  []rotationEntry
+		pending []rotationEntry
+	)
+
+	for _, e := range entries {
+		exp, expires := p.expiration(e)
+
+		switch e.status.State {
+		case StateActive:
+			if !expires || !now.After(exp) {
+				active = append(active, e)
+				continue
+			}
+			// Retire the key, it still verifies during the grace period
+			plan.retire = append(plan.retire, e.key.ID())
+			if now.After(exp.Add(p.GracePeriod)) {
+				plan.destroy = append(plan.destroy, e.key.ID())
+			}
+		case StatePending:
+			pending = append(pending, e)
+		case StateRetired, StateRevoked:
+			// Grace period starts at the earliest of expiration and retirement
+			since, _ := e.status.Since(e.status.State)
+			if expires && exp.Before(since) {
+				since = exp
+			}
+			if now.After(since.Add(p.GracePeriod)) {
+				plan.destroy = append(plan.destroy, e.key.ID())
+			}
+		}
+	}
+
+	// Activate the keys published for the whole lead time
+	sortByCreation(pending)
+	if p.PrePublish > 0 {
+		var waiting []rotationEntry
+		for _, e := range pending {
+			if since, _ := e.status.Since(StatePending); !now.Before(since.Add(p.PrePublish)) {
+				plan.activate = append(plan.activate, e.key.ID())
+				active = append(active, e)
+				continue
+			}
+			waiting = append(waiting, e)
+		}
+		pending = waiting
+	}
+
+	// Keep the minimal count of active keys, published keys first
+	for len(active) < p.MinActive && len(pending) > 0 {
+		plan.activate = append(plan.activate, pending[0].key.ID())
+		active, pending = append(active, pending[0]), pending[1:]
+	}
+	if missing := p.MinActive - len(active); missing > 0 {
+		plan.generateActive = missing
+	}
+
+	// Retire the oldest keys above the maximal count
+	if p.MaxActive > 0 && len(active) > p.MaxActive {
+		sortByCreation(active)
+		for _, e := range active[:len(active)-p.MaxActive] {
+			plan.retire = append(plan.retire, e.key.ID())
+		}
+		active = active[len(active)-p.MaxActive:]
+	}
+
+	// Publish the replacements of the keys expiring within the lead time
+	if p.PrePublish > 0 && p.MinActive > 0 {
+		lasting := plan.generateActive
+		for _, e := range active {
+			if exp, expires := p.expiration(e); !expires || exp.After(now.Add(p.PrePublish)) {
+				lasting++
+			}
+		}
+		if missing := p.MinActive - lasting - len(pending); missing > 0 {
+			plan.generatePending = missing
+		}
+	}
+
+	return plan
+}
+
+// sortByCreation sorts entries from the oldest to the newest key
+func sortByCreation(entries []rotationEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key.CreatedAt().Before(entries[j].key.CreatedAt())
+	})
+}
+
+// applyRotation applies the plan to the keystore, generating the new keys
+// with its KeyGenerator. All changes are attempted, the first error is
+// returned.
+func applyRotation(ctx context.Context, ks KeyStore, plan *rotationPlan) error {
+	var errs []error
+
+	transition := func(ids []string, to State) {
+		for _, id := range ids {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, err)
+				return
+			}
+			if err := ks.Transition(id, to); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %v", id, err))
+			}
+		}
+	}
+
+	generate := func(count int, add func(key.Key) error) {
+		for i := 0; i < count; i++ {
+			if err := ctx.Err(); err != nil {
+				errs = append(errs, err)
+				return
+			}
+			k, err := ks.Generate()
+			if err == nil {
+				err = add(k)
+			}
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	transition(plan.retire, StateRetired)
+	transition(plan.activate, StateActive)
+	generate(plan.generateActive, ks.Add)
+	generate(plan.generatePending, ks.AddPending)
+	transition(plan.destroy, StateDestroyed)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("keystore: Unable to rotate keys %v", errs[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package keystore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"go.zenithar.org/keystore/key"
+)
+
+// clockedKeyStore returns an in-memory keystore whose clock and generated key
+// creation dates are driven by now
+func clockedKeyStore(now *time.Time, opts ...Option) KeyStore {
+	generator := func() (key.Key, error) {
+		k, err := key.Ed25519()
+		if err != nil {
+			return nil, err
+		}
+		k.Metadata().IssuedAt = now.UTC()
+		return k, nil
+	}
+
+	ks, err := NewInMemory(generator, opts...)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+	ks.(*inMemoryKeyStore).now = func() time.Time { return *now }
+
+	return ks
+}
+
+func stateCount(ks KeyStore, state State) int {
+	keys, err := ks.ByState(state)
+	Expect(err).To(BeNil(), "Error should be nil on query")
+	return len(keys)
+}
+
+func TestRotationPolicy_Validate(t *testing.T) {
+	RegisterTestingT(t)
+
+	p := DefaultRotationPolicy()
+	Expect(p.validate()).To(BeNil())
+
+	p.MinActive, p.MaxActive = 3, 2
+	Expect(p.validate()).To(Equal(ErrInvalidRotationPolicy))
+
+	p = DefaultRotationPolicy()
+	p.GracePeriod = -time.Second
+	Expect(p.validate()).To(Equal(ErrInvalidRotationPolicy))
+
+	_, err := NewInMemory(key.Ed25519, WithRotationPolicy(RotationPolicy{MinActive: -1}))
+	Expect(err).To(Equal(ErrInvalidRotationPolicy))
+}
+
+func TestRotationPolicy_Rollover(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ks := clockedKeyStore(&now, WithRotationPolicy(RotationPolicy{
+		KeyLifetime: 24 * time.Hour,
+		PrePublish:  2 * time.Hour,
+		GracePeriod: time.Hour,
+		MinActive:   1,
+	}))
+	ctx := context.Background()
+
+	// Empty keystore gets an active key
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	active, _ := ks.ByState(StateActive)
+	Expect(active).To(HaveLen(1), "Rotation should generate an active key")
+	current := active[0]
+
+	// Nothing to do in the middle of the lifetime
+	now = now.Add(12 * time.Hour)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	Expect(stateCount(ks, StatePending)).To(Equal(0), "No key should be published yet")
+
+	// Replacement is published ahead of the expiration
+	now = now.Add(11 * time.Hour)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	pending, _ := ks.ByState(StatePending)
+	Expect(pending).To(HaveLen(1), "Replacement key should be published")
+	next := pending[0]
+
+	set, _ := ks.PublicKeySet()
+	_, ok := set.LookupKeyID(next.ID())
+	Expect(ok).To(BeTrue(), "Replacement key should be in the public key set")
+
+	k, _ := ks.Pick()
+	Expect(k.ID()).To(Equal(current.ID()), "Current key should still be used")
+
+	// Expired key is retired, replacement is activated
+	now = now.Add(2 * time.Hour)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	status, _ := ks.Status(current.ID())
+	Expect(status.State).To(Equal(StateRetired), "Expired key should be retired")
+	status, _ = ks.Status(next.ID())
+	Expect(status.State).To(Equal(StateActive), "Replacement key should be activated")
+	Expect(stateCount(ks, StatePending)).To(Equal(0), "No extra key should be published")
+
+	k, _ = ks.Pick()
+	Expect(k.ID()).To(Equal(next.ID()), "Replacement key should be used")
+	_, err := ks.Get(current.ID())
+	Expect(err).To(BeNil(), "Retired key should still verify")
+
+	// Destroyed after the grace period
+	now = now.Add(time.Hour + time.Minute)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	_, err = ks.Status(current.ID())
+	Expect(err).To(Equal(ErrKeyNotFound), "Retired key should be destroyed")
+	Expect(stateCount(ks, StateActive)).To(Equal(1), "Only the replacement key should be active")
+}
+
+func TestRotationPolicy_PrePublishActivation(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ks := clockedKeyStore(&now, WithRotationPolicy(RotationPolicy{
+		PrePublish:  time.Hour,
+		GracePeriod: time.Hour,
+		MinActive:   1,
+		MaxActive:   1,
+	}))
+	ctx := context.Background()
+
+	current, _ := ks.Generate()
+	Expect(ks.Add(current)).To(BeNil(), "Error should be nil on addition")
+	now = now.Add(time.Minute)
+	next, _ := ks.Generate()
+	Expect(ks.AddPending(next)).To(BeNil(), "Error should be nil on addition")
+
+	// Published keys wait for the lead time
+	now = now.Add(30 * time.Minute)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	status, _ := ks.Status(next.ID())
+	Expect(status.State).To(Equal(StatePending), "Key should stay pending during the lead time")
+
+	// Activated even though enough keys are active, the oldest one is retired
+	now = now.Add(30 * time.Minute)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	status, _ = ks.Status(next.ID())
+	Expect(status.State).To(Equal(StateActive), "Key should be activated after the lead time")
+	status, _ = ks.Status(current.ID())
+	Expect(status.State).To(Equal(StateRetired), "Previous key should be retired")
+}
+
+func TestRotationPolicy_Default(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(DefaultRotationPolicy().MinActive).To(Equal(1))
+
+	ks, err := NewInMemory(key.Ed25519)
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+	k, err := ks.Pick()
+	Expect(err).To(BeNil(), "Rotation should generate a signing key")
+	Expect(k.HasPrivate()).To(BeTrue())
+}
+
+func TestRotationPolicy_Options(t *testing.T) {
+	RegisterTestingT(t)
+
+	policy := RotationPolicy{MinActive: 2}
+
+	o := applyOptions([]Option{WithMaxKeyAge(time.Hour), WithGracePeriod(time.Minute), WithRotationPolicy(policy)})
+	Expect(o.rotation.KeyLifetime).To(Equal(time.Hour), "Maximal age should not be dropped by the policy")
+	Expect(o.rotation.GracePeriod).To(Equal(time.Minute), "Grace period should not be dropped by the policy")
+	Expect(o.rotation.MinActive).To(Equal(2))
+
+	o = applyOptions([]Option{WithRotationPolicy(policy), WithMaxKeyAge(time.Hour), WithGracePeriod(time.Minute)})
+	Expect(o.rotation.KeyLifetime).To(Equal(time.Hour), "Options order should not matter")
+	Expect(o.rotation.GracePeriod).To(Equal(time.Minute), "Options order should not matter")
+
+	o = applyOptions([]Option{WithGracePeriod(time.Minute)})
+	Expect(o.rotation.GracePeriod).To(Equal(time.Minute), "Grace period should override the default policy")
+
+	policy.KeyLifetime, policy.GracePeriod = 2*time.Hour, 3*time.Hour
+	o = applyOptions([]Option{WithMaxKeyAge(time.Hour), WithGracePeriod(time.Minute), WithRotationPolicy(policy)})
+	Expect(o.rotation.KeyLifetime).To(Equal(2*time.Hour), "Policy should take precedence")
+	Expect(o.rotation.GracePeriod).To(Equal(3*time.Hour), "Policy should take precedence")
+}
+
+func TestRotationPolicy_MaxActive(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ks := clockedKeyStore(&now, WithRotationPolicy(RotationPolicy{
+		GracePeriod: time.Hour,
+		MaxActive:   2,
+	}))
+
+	var keys []key.Key
+	for i := 0; i < 3; i++ {
+		k, _ := ks.Generate()
+		Expect(ks.Add(k)).To(BeNil(), "Error should be nil on addition")
+		keys = append(keys, k)
+		now = now.Add(time.Minute)
+	}
+
+	Expect(ks.RotateKeys(context.Background())).To(BeNil(), "Error should be nil on rotation")
+	Expect(stateCount(ks, StateActive)).To(Equal(2), "Active keys should be limited")
+
+	status, _ := ks.Status(keys[0].ID())
+	Expect(status.State).To(Equal(StateRetired), "Oldest key should be retired")
+}
+
+func TestRotationPolicy_CheckInterval(t *testing.T) {
+	RegisterTestingT(t)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ks := clockedKeyStore(&now, WithRotationPolicy(RotationPolicy{
+		KeyLifetime:   time.Hour,
+		MinActive:     1,
+		CheckInterval: 10 * time.Minute,
+	}))
+	ctx := context.Background()
+
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	Expect(stateCount(ks, StateActive)).To(Equal(1), "Rotation should generate an active key")
+
+	Expect(ks.Transition(func() string {
+		active, _ := ks.ByState(StateActive)
+		return active[0].ID()
+	}(), StateRevoked)).To(BeNil(), "Error should be nil on revocation")
+
+	now = now.Add(5 * time.Minute)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	Expect(stateCount(ks, StateActive)).To(Equal(0), "Rotation should be throttled")
+
+	now = now.Add(5 * time.Minute)
+	Expect(ks.RotateKeys(ctx)).To(BeNil(), "Error should be nil on rotation")
+	Expect(stateCount(ks, StateActive)).To(Equal(1), "Revoked key should be replaced")
+}
@@ -0,0 +1,239 @@
+package keystore
+
+import (
+	"container/list"
+	"context"
+	"math/rand"
+	"sync"
+
+	"go.zenithar.org/keystore/key"
+)
+
+// Selector chooses the key returned by Pick among the signing keys of the
+// keystore. candidates is never empty. Select is called concurrently while the
+// keystore holds its lock: a Selector must be safe for concurrent use, must
+// not call the keystore, and must not keep the candidates, which are wiped
+// when removed from the keystore.
+type Selector interface {
+	Select(ctx context.Context, candidates []key.Key) (key.Key, error)
+}
+
+// SelectorFunc adapts a function to the Selector interface
+type SelectorFunc func(context.Context, []key.Key) (key.Key, error)
+
+// Select calls f(ctx, candidates)
+func (f SelectorFunc) Select(ctx context.Context, candidates []key.Key) (key.Key, error) {
+	return f(ctx, candidates)
+}
+
+type callerKey struct{}
+
+// WithCaller attaches the caller identity used by the Sticky selector
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func callerFromContext(ctx context.Context) (string, bool) {
+	caller, ok := ctx.Value(callerKey{}).(string)
+	return caller, ok && len(caller) > 0
+}
+
+// -----------------------------------------------------------------------------
+
+type roundRobinSelector struct {
+	sync.Mutex
+	cursor int
+}
+
+// RoundRobin spreads the signatures over all candidates in turn
+func RoundRobin() Selector {
+	return &roundRobinSelector{}
+}
+
+func (s *roundRobinSelector) Select(_ context.Context, candidates []key.Key) (key.Key, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.cursor = (s.cursor + 1) % len(candidates)
+	return candidates[s.cursor], nil
+}
+
+// NewestActive always selects the most recently created candidate
+func NewestActive() Selector {
+	return SelectorFunc(func(_ context.Context, candidates []key.Key) (key.Key, error) {
+		if len(candidates) == 0 {
+			return nil, ErrNoSigningKey
+		}
+
+		newest := candidates[0]
+		for _, k := range candidates[1:] {
+			if k.CreatedAt().After(newest.CreatedAt()) {
+				newest = k
+			}
+		}
+		return newest, nil
+	})
+}
+
+// Random selects a candidate uniformly at random
+func Random() Selector {
+	return SelectorFunc(func(_ context.Context, candidates []key.Key) (key.Key, error) {
+		if len(candidates) == 0 {
+			return nil, ErrNoSigningKey
+		}
+		return candidates[rand.Intn(len(candidates))], nil
+	})
+}
+
+type leastUsedSelector struct {
+	sync.Mutex
+	usage map[string]uint64
+}
+
+// LeastUsed selects the candidate this selector returned the fewest times
+func LeastUsed() Selector {
+	return &leastUsedSelector{
+		usage: make(map[string]uint64),
+	}
+}
+
+func (s *leastUsedSelector) Select(_ context.Context, candidates []key.Key) (key.Key, error) {
+	if len(candidates) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	// Forget the keys which are not candidates anymore
+	known := make(map[string]uint64, len(candidates))
+	for _, k := range candidates {
+		known[k.ID()] = s.usage[k.ID()]
+	}
+	s.usage = known
+
+	selected := candidates[0]
+	for _, k := range candidates[1:] {
+		if s.usage[k.ID()] < s.usage[selected.ID()] {
+			selected = k
+		}
+	}
+	s.usage[selected.ID()]++
+
+	return selected, nil
+}
+
+// stickyMaxCallers bounds the count of callers remembered by Sticky, the
+// least recently seen ones are forgotten first
+const stickyMaxCallers = 10000
+
+type stickySelector struct {
+	sync.Mutex
+	fallback Selector
+	// callers indexes the elements of order by caller
+	callers map[string]*list.Element
+	// order lists the assignments from the most recently seen caller
+	order *list.List
+	// kids are the candidates of the previous selection
+	kids []string
+}
+
+// stickyAssignment is the key assigned to a caller
+type stickyAssignment struct {
+	caller string
+	kid    string
+}
+
+// Sticky always selects the same candidate for a caller (see WithCaller), as
+// long as this candidate is available. New callers, and calls without caller,
+// are assigned using fallback, RoundRobin when nil. At most 10000 callers are
+// remembered, the least recently seen ones being reassigned first.
+func Sticky(fallback Selector) Selector {
+	if fallback == nil {
+		fallback = RoundRobin()
+	}
+	return &stickySelector{
+		fallback: fallback,
+		callers:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (s *stickySelector) Select(ctx context.Context, candidates []key.Key) (key.Key, error) {
+	caller, ok := callerFromContext(ctx)
+	if !ok {
+		return s.fallback.Select(ctx, candidates)
+	}
+
+	s.Lock()
+	defer s.Unlock()
+
+	s.forgetRemoved(candidates)
+
+	if e, ok := s.callers[caller]; ok {
+		kid := e.Value.(*stickyAssignment).kid
+		for _, k := range candidates {
+			if k.ID() == kid {
+				s.order.MoveToFront(e)
+				return k, nil
+			}
+		}
+	}
+
+	k, err := s.fallback.Select(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	s.assign(caller, k.ID())
+
+	return k, nil
+}
+
+// forgetRemoved drops the assignments to keys which are not candidates
+// anymore, when the candidates changed since the previous selection
+func (s *stickySelector) forgetRemoved(candidates []key.Key) {
+	changed := len(candidates) != len(s.kids)
+	for i := 0; !changed && i < len(candidates); i++ {
+		changed = candidates[i].ID() != s.kids[i]
+	}
+	if !changed {
+		return
+	}
+
+	s.kids = s.kids[:0]
+	known := make(map[string]bool, len(candidates))
+	for _, k := range candidates {
+		s.kids = append(s.kids, k.ID())
+		known[k.ID()] = true
+	}
+
+	for e := s.order.Front(); e != nil; {
+		next := e.Next()
+		if a := e.Value.(*stickyAssignment); !known[a.kid] {
+			s.order.Remove(e)
+			delete(s.callers, a.caller)
+		}
+		e = next
+	}
+}
+
+// assign remembers the key of the caller, forgetting the least recently seen
+// caller when full
+func (s *stickySelector) assign(caller, kid string) {
+	if e, ok := s.callers[caller]; ok {
+		e.Value.(*stickyAssignment).kid = kid
+		s.order.MoveToFront(e)
+		return
+	}
+
+	if s.order.Len() >= stickyMaxCallers {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.callers, oldest.Value.(*stickyAssignment).caller)
+	}
+	s.callers[caller] = s.order.PushFront(&stickyAssignment{caller: caller, kid: kid})
+}
@@ -0,0 +1,216 @@
+package keystore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"go.zenithar.org/keystore/key"
+)
+
+func generateKeys(n int) []key.Key {
+	keys := make([]key.Key, n)
+	for i := range keys {
+		keys[i], _ = key.Ed25519()
+	}
+	return keys
+}
+
+func TestSelector_RoundRobin(t *testing.T) {
+	RegisterTestingT(t)
+
+	keys := generateKeys(3)
+	s := RoundRobin()
+
+	seen := map[string]int{}
+	for i := 0; i < 6; i++ {
+		k, err := s.Select(context.Background(), keys)
+		Expect(err).To(BeNil(), "Error should be nil on selection")
+		seen[k.ID()]++
+	}
+	for _, k := range keys {
+		Expect(seen[k.ID()]).To(Equal(2), "Each key should be selected in turn")
+	}
+
+	_, err := s.Select(context.Background(), nil)
+	Expect(err).To(Equal(ErrNoSigningKey), "Empty candidates should raise an error")
+}
+
+func TestSelector_NewestActive(t *testing.T) {
+	RegisterTestingT(t)
+
+	older, err := key.FromString([]byte(`{"kty":"OKP","crv":"Ed25519","iat":1500000000,"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`))
+	Expect(err).To(BeNil(), "Error should be nil on decoding")
+	newest, _ := key.Ed25519()
+
+	k, err := NewestActive().Select(context.Background(), []key.Key{newest, older})
+	Expect(err).To(BeNil(), "Error should be nil on selection")
+	Expect(k.ID()).To(Equal(newest.ID()), "Newest key should be selected")
+}
+
+func TestSelector_Random(t *testing.T) {
+	RegisterTestingT(t)
+
+	keys := generateKeys(3)
+	s := Random()
+
+	for i := 0; i < 10; i++ {
+		k, err := s.Select(context.Background(), keys)
+		Expect(err).To(BeNil(), "Error should be nil on selection")
+		Expect(keys).To(ContainElement(k), "Selected key should be a candidate")
+	}
+}
+
+func TestSelector_LeastUsed(t *testing.T) {
+	RegisterTestingT(t)
+
+	keys := generateKeys(2)
+	s := LeastUsed()
+
+	for i := 0; i < 4; i++ {
+		_, err := s.Select(context.Background(), keys)
+		Expect(err).To(BeNil(), "Error should be nil on selection")
+	}
+
+	// A new key is used until it catches up
+	keys = append(keys, generateKeys(1)...)
+	for i := 0; i < 2; i++ {
+		k, err := s.Select(context.Background(), keys)
+		Expect(err).To(BeNil(), "Error should be nil on selection")
+		Expect(k.ID()).To(Equal(keys[2].ID()), "Least used key should be selected")
+	}
+}
+
+func TestSelector_Sticky(t *testing.T) {
+	RegisterTestingT(t)
+
+	keys := generateKeys(3)
+	s := Sticky(nil)
+
+	alice := WithCaller(context.Background(), "alice")
+	bob := WithCaller(context.Background(), "bob")
+
+	a, _ := s.Select(alice, keys)
+	b, _ := s.Select(bob, keys)
+	Expect(a.ID()).ToNot(Equal(b.ID()), "Callers should be spread over the keys")
+
+	for i := 0; i < 3; i++ {
+		k, err := s.Select(alice, keys)
+		Expect(err).To(BeNil(), "Error should be nil on selection")
+		Expect(k.ID()).To(Equal(a.ID()), "Caller should always get the same key")
+	}
+
+	// Reassigned when the key is not available anymore
+	var remaining []key.Key
+	for _, k := range keys {
+		if k.ID() != a.ID() {
+			remaining = append(remaining, k)
+		}
+	}
+	k, err := s.Select(alice, remaining)
+	Expect(err).To(BeNil(), "Error should be nil on selection")
+	Expect(k.ID()).ToNot(Equal(a.ID()), "Unavailable key should not be selected")
+}
+
+func TestSelector_StickyBounded(t *testing.T) {
+	RegisterTestingT(t)
+
+	keys := generateKeys(2)
+	s := Sticky(nil).(*stickySelector)
+
+	for i := 0; i < stickyMaxCallers+10; i++ {
+		_, err := s.Select(WithCaller(context.Background(), fmt.Sprintf("caller-%d", i)), keys)
+		Expect(err).To(BeNil(), "Error should be nil on selection")
+	}
+	Expect(s.callers).To(HaveLen(stickyMaxCallers), "Callers should be bounded")
+	Expect(s.order.Len()).To(Equal(stickyMaxCallers))
+	Expect(s.callers).ToNot(HaveKey("caller-0"), "Least recently seen caller should be forgotten")
+	Expect(s.callers).To(HaveKey(fmt.Sprintf("caller-%d", stickyMaxCallers+9)))
+
+	// Callers of removed keys are forgotten
+	_, err := s.Select(context.Background(), keys[:1])
+	Expect(err).To(BeNil(), "Error should be nil on selection")
+	Expect(s.callers).To(HaveLen(stickyMaxCallers), "Selections without caller should keep the callers")
+
+	_, err = s.Select(WithCaller(context.Background(), "caller-0"), keys[:1])
+	Expect(err).To(BeNil(), "Error should be nil on selection")
+	for caller, e := range s.callers {
+		Expect(e.Value.(*stickyAssignment).kid).To(Equal(keys[0].ID()), caller)
+	}
+	Expect(s.callers).To(HaveLen(stickyMaxCallers/2+1), "Callers of the removed key should be forgotten")
+}
+
+func TestInMemoryKeystore_Selector(t *testing.T) {
+	RegisterTestingT(t)
+
+	ks, err := NewInMemory(key.Ed25519, WithSelector(Sticky(nil)))
+	Expect(err).To(BeNil(), "Error should be nil on construction")
+
+	for i := 0; i < 3; i++ {
+		k, _ := ks.Generate()
+		Expect(ks.Add(k)).To(BeNil(), "Error should be nil on addition")
+	}
+
+	ctx := WithCaller(context.Background(), "batch")
+	first, err := ks.PickContext(ctx)
+	Expect(err).To(BeNil(), "Error should be nil on pick")
+	for i := 0; i < 3; i++ {
+		k, err := ks.PickContext(ctx)
+		Expect(err).To(BeNil(), "Error should be nil on pick")
+		Expect(k.ID()).To(Equal(first.ID()), "Caller should always get the same key")
+	}
+}
+
+func TestInMemoryKeystore_ConcurrentPick(t *testing.T) {
+	RegisterTestingT(t)
+
+	selectors := map[string]Selector{
+		"RoundRobin":   RoundRobin(),
+		"NewestActive": NewestActive(),
+		"Random":       Random(),
+		"LeastUsed":    LeastUsed(),
+		"Sticky":       Sticky(nil),
+	}
+
+	for name, selector := range selectors {
+		ks, err := NewInMemory(key.Ed25519, WithSelector(selector))
+		Expect(err).To(BeNil(), name)
+
+		stable, _ := ks.Generate()
+		Expect(ks.Add(stable)).To(BeNil(), name)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 64)
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				ctx := WithCaller(context.Background(), fmt.Sprintf("caller-%d", i%3))
+				for j := 0; j < 50; j++ {
+					k, err := ks.PickContext(ctx)
+					if err == nil {
+						_, err = k.Sign([]byte("payload"))
+					}
+					if err != nil {
+						errs <- err
+						return
+					}
+				}
+			}(i)
+		}
+
+		// Keys are added and removed while picking
+		for i := 0; i < 20; i++ {
+			k, _ := ks.Generate()
+			Expect(ks.Add(k)).To(BeNil(), name)
+			Expect(ks.Remove(k.ID())).To(BeNil(), name)
+		}
+
+		wg.Wait()
+		close(errs)
+		Expect(errs).To(BeEmpty(), name)
+	}
+}